@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxInflight is the maximum number of asynchronous operations a client runs at the same time.
+const maxInflight = 16
+
+// readFuture is the result of an asynchronous read.
+type readFuture struct {
+	resp *proto.ReadResponse
+	err  error
+	done chan struct{}
+}
+
+// Get blocks until the read has completed on the configuration and all its successors.
+func (f *readFuture) Get() (*proto.ReadResponse, error) {
+	<-f.done
+	return f.resp, f.err
+}
+
+// Done reports if the read has completed.
+func (f *readFuture) Done() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeFuture is the result of an asynchronous write.
+type writeFuture struct {
+	resp *proto.WriteResponse
+	err  error
+	done chan struct{}
+}
+
+// Get blocks until the write has completed on the configuration and all its successors.
+func (f *writeFuture) Get() (*proto.WriteResponse, error) {
+	<-f.done
+	return f.resp, f.err
+}
+
+// Done reports if the write has completed.
+func (f *writeFuture) Done() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// readAsync starts a read of key at the client's default consistency level and returns a future for its result.
+// It uses the client's thrifty and hedged modes like read, but is not part of a session.
+// The quorum call on the current configuration is sent immediately;
+// successor configurations are visited once it has resolved.
+// readAsync blocks while maxInflight operations are running.
+func (c *client) readAsync(key string) *readFuture {
+	f := &readFuture{done: make(chan struct{})}
+	c.inflight <- struct{}{}
+	start := c.current()
+	cfg, err := c.parseConfiguration(start)
+	if err != nil {
+		f.err = err
+		close(f.done)
+		<-c.inflight
+		return f
+	}
+	level := c.consistency()
+	if isCoded(start) || c.isThrifty() || c.isHedged() {
+		// erasure-coded, thrifty and hedged reads make several calls per configuration,
+		// and are run synchronously in the background
+		go func() {
+			defer func() { <-c.inflight }()
+			defer close(f.done)
+			f.resp, f.err = c.readFrom(key, level, start, nil)
+		}()
+		return f
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	fut := cfg.ReadQCAsync(ctx, &proto.ReadRequest{Key: key, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: start.GetTime()})
+	go func() {
+		defer func() { <-c.inflight }()
+		defer close(f.done)
+		first, err := fut.Get()
+		cancel()
+		if err != nil {
+			err = quorumError("ReadQCAsync", err)
+			if !c.retry.shouldRetry(err) {
+				f.err = err
+				return
+			}
+			// fall back to the synchronous call and its retry policy
+			first = nil
+		}
+		f.resp, f.err = c.readFrom(key, level, start, first)
+	}()
+	return f
+}
+
+// writeAsync starts a write of key at the client's default consistency level and returns a future for its result.
+// It uses the client's thrifty and hedged modes like write, but is not part of a session.
+// The quorum call on the current configuration is sent immediately;
+// successor configurations are visited once it has resolved.
+// writeAsync blocks while maxInflight operations are running.
+func (c *client) writeAsync(key, value string) *writeFuture {
+	f := &writeFuture{done: make(chan struct{})}
+	c.inflight <- struct{}{}
+	start := c.current()
+	cfg, err := c.parseConfiguration(start)
+	if err != nil {
+		f.err = err
+		close(f.done)
+		<-c.inflight
+		return f
+	}
+	ts := timestamppb.Now()
+	level := c.consistency()
+	if isCoded(start) || c.isThrifty() || c.isHedged() {
+		// erasure-coded, thrifty and hedged writes make several calls per configuration,
+		// and are run synchronously in the background
+		go func() {
+			defer func() { <-c.inflight }()
+			defer close(f.done)
+			f.resp, f.err = c.writeFrom(key, value, ts, level, start, nil)
+		}()
+		return f
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	req := &proto.WriteRequest{Key: key, Value: value, Time: ts, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: start.GetTime()}
+	c.keys.signWrite(req)
+	fut := cfg.WriteQCAsync(ctx, req)
+	go func() {
+		defer func() { <-c.inflight }()
+		defer close(f.done)
+		first, err := fut.Get()
+		cancel()
+		if err != nil {
+			err = quorumError("WriteQCAsync", err)
+			if !c.retry.shouldRetry(err) {
+				f.err = err
+				return
+			}
+			// fall back to the synchronous call and its retry policy
+			first = nil
+		}
+		f.resp, f.err = c.writeFrom(key, value, ts, level, start, first)
+	}()
+	return f
+}
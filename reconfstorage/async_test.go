@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"testing"
+)
+
+// newTestCluster starts n servers on free ports and returns a client of the configuration of all of them.
+func newTestCluster(t *testing.T, n int) (*client, []string) {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	addrs := make([]string, n)
+	for i := range addrs {
+		srv, addr := startServer("127.0.0.1:0")
+		t.Cleanup(srv.Stop)
+		addrs[i] = addr
+	}
+	return newClient(addrs, newKeyring()), addrs
+}
+
+func TestPipelinedWrites(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	const n = 3 * maxInflight
+	// writes to the same key are ordered by the time they were issued, whatever order they complete in
+	futures := make([]*writeFuture, n)
+	for i := range futures {
+		futures[i] = c.writeAsync("k", fmt.Sprint(i))
+	}
+	others := make([]*writeFuture, n)
+	for i := range others {
+		others[i] = c.writeAsync(fmt.Sprintf("k%02d", i), fmt.Sprint(i))
+	}
+	for i, f := range append(futures, others...) {
+		if _, err := f.Get(); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+		if !f.Done() {
+			t.Fatalf("write %d not done after Get", i)
+		}
+	}
+	if len(c.inflight) != 0 {
+		t.Errorf("%d operations still in flight", len(c.inflight))
+	}
+
+	if r, err := c.readAsync("k").Get(); err != nil || r.GetValue() != fmt.Sprint(n-1) {
+		t.Errorf("read k = %v, %v; want the last write %d", r.GetValue(), err, n-1)
+	}
+	reads := make([]*readFuture, n)
+	for i := range reads {
+		reads[i] = c.readAsync(fmt.Sprintf("k%02d", i))
+	}
+	for i, f := range reads {
+		if r, err := f.Get(); err != nil || r.GetValue() != fmt.Sprint(i) {
+			t.Errorf("read k%02d = %v, %v; want %d", i, r.GetValue(), err, i)
+		}
+	}
+}
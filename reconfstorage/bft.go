@@ -0,0 +1,270 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"reconfstorage/proto"
+
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// keyring signs the values and configurations written by the client,
+// and holds the public keys of the writers whose signatures it accepts.
+type keyring struct {
+	key     ed25519.PrivateKey
+	trusted map[string]bool
+}
+
+// newKeyring returns a keyring with a new random key that trusts itself and the given writers.
+func newKeyring(trusted ...ed25519.PublicKey) *keyring {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return withKey(key, trusted...)
+}
+
+// isByzantine reports whether the configuration conf tolerates Byzantine servers.
+func isByzantine(conf *proto.MetaConfig) bool {
+	_, system := splitConfig(conf.GetAdds())
+	return strings.HasPrefix(system, "bft:")
+}
+
+// trustedKeys returns a keyring that verifies the signatures of the given writers, but cannot sign.
+func trustedKeys(trusted ...ed25519.PublicKey) *keyring {
+	k := &keyring{trusted: make(map[string]bool)}
+	for _, pub := range trusted {
+		k.trusted[string(pub)] = true
+	}
+	return k
+}
+
+func withKey(key ed25519.PrivateKey, trusted ...ed25519.PublicKey) *keyring {
+	k := &keyring{key: key, trusted: make(map[string]bool)}
+	k.trusted[string(k.public())] = true
+	for _, pub := range trusted {
+		k.trusted[string(pub)] = true
+	}
+	return k
+}
+
+// loadKeyring returns a keyring with the key stored hex-encoded in file, creating the file if it does not exist.
+// trust is a comma-separated list of hex-encoded public keys of other writers.
+// Without a file, a random key is used.
+func loadKeyring(file, trust string) (*keyring, error) {
+	trusted, err := parseTrusted(trust)
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return newKeyring(trusted...), nil
+	}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		k := newKeyring(trusted...)
+		return k, os.WriteFile(file, []byte(hex.EncodeToString(k.key.Seed())), 0o600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid key in '%s'", file)
+	}
+	return withKey(ed25519.NewKeyFromSeed(seed), trusted...), nil
+}
+
+// parseTrusted parses a comma-separated list of hex-encoded public keys.
+func parseTrusted(trust string) ([]ed25519.PublicKey, error) {
+	var trusted []ed25519.PublicKey
+	for _, s := range strings.Split(trust, ",") {
+		if s == "" {
+			continue
+		}
+		pub, err := hex.DecodeString(s)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key '%s'", s)
+		}
+		trusted = append(trusted, pub)
+	}
+	return trusted, nil
+}
+
+func (k *keyring) public() ed25519.PublicKey {
+	return k.key.Public().(ed25519.PublicKey)
+}
+
+// signWrite signs the key, value and timestamp of req.
+func (k *keyring) signWrite(req *proto.WriteRequest) {
+	req.Writer = k.public()
+	req.Signature = ed25519.Sign(k.key, writeDigest(req.GetKey(), req.GetValue(), req.GetTime()))
+}
+
+// signConfig returns a signed copy of conf.
+func (k *keyring) signConfig(conf *proto.MetaConfig) *proto.MetaConfig {
+	signed := pb.Clone(conf).(*proto.MetaConfig)
+	signed.Writer = k.public()
+	signed.Signature = ed25519.Sign(k.key, configDigest(signed))
+	return signed
+}
+
+// signTombstone signs the configuration and server of t.
+func (k *keyring) signTombstone(t *proto.Tombstone) {
+	t.Writer = k.public()
+	t.Signature = ed25519.Sign(k.key, tombstoneDigest(t))
+}
+
+// verifyValue reports whether resp is the value of key signed by a trusted writer.
+func (k *keyring) verifyValue(key string, resp *proto.ReadResponse) bool {
+	return resp.GetOK() && k.verify(resp.GetWriter(), writeDigest(key, resp.GetValue(), resp.GetTime()), resp.GetSignature())
+}
+
+// verifyConfig reports whether conf is signed by a trusted writer.
+func (k *keyring) verifyConfig(conf *proto.MetaConfig) bool {
+	return k.verify(conf.GetWriter(), configDigest(conf), conf.GetSignature())
+}
+
+// verifyTombstone reports whether t is signed by a trusted writer.
+func (k *keyring) verifyTombstone(t *proto.Tombstone) bool {
+	return k.verify(t.GetWriter(), tombstoneDigest(t), t.GetSignature())
+}
+
+func (k *keyring) verify(writer, msg, sig []byte) bool {
+	if k == nil || !k.trusted[string(writer)] || len(writer) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(writer, msg, sig)
+}
+
+// writeDigest returns the bytes signed for a write.
+func writeDigest(key, value string, ts *timestamppb.Timestamp) []byte {
+	b, _ := pb.MarshalOptions{Deterministic: true}.Marshal(&proto.WriteRequest{Key: key, Value: value, Time: ts})
+	return b
+}
+
+// configDigest returns the bytes signed for a configuration, that is all fields except the signature.
+func configDigest(conf *proto.MetaConfig) []byte {
+	c := pb.Clone(conf).(*proto.MetaConfig)
+	c.Signature, c.Writer = nil, nil
+	b, _ := pb.MarshalOptions{Deterministic: true}.Marshal(c)
+	return b
+}
+
+// tombstoneDigest returns the bytes signed for a tombstone, that is all fields except the signature.
+func tombstoneDigest(t *proto.Tombstone) []byte {
+	b, _ := pb.MarshalOptions{Deterministic: true}.Marshal(&proto.Tombstone{Config: t.GetConfig(), Server: t.GetServer()})
+	return b
+}
+
+// vouchedValue returns the newest value of key that is either signed by a trusted writer
+// or reported by at least f+1 replicas, so that at least one correct replica stores it.
+// It returns nil if no value qualifies.
+func (q qspec) vouchedValue(key string, replies map[uint32]*proto.ReadResponse) *proto.ReadResponse {
+	type candidate struct {
+		resp  *proto.ReadResponse
+		count int
+	}
+	byValue := make(map[string]*candidate)
+	for _, r := range replies {
+		v := pb.Clone(r).(*proto.ReadResponse)
+		// replicas that agree on the value may differ in what they know about configurations
+		v.MConfigs, v.ConfigDigest, v.Fenced, v.Signature, v.Writer = nil, nil, false, nil, nil
+		b, _ := pb.MarshalOptions{Deterministic: true}.Marshal(v)
+		c, ok := byValue[string(b)]
+		if !ok {
+			c = &candidate{resp: r}
+			byValue[string(b)] = c
+		}
+		c.count++
+		// prefer a signed copy of the value
+		if q.keys.verifyValue(key, r) {
+			c.resp = r
+		}
+	}
+	candidates := make([]*candidate, 0, len(byValue))
+	for _, c := range byValue {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[j].resp.GetTime().AsTime().Before(candidates[i].resp.GetTime().AsTime())
+	})
+	for _, c := range candidates {
+		if c.count > q.faults || q.keys.verifyValue(key, c.resp) {
+			return c.resp
+		}
+	}
+	return nil
+}
+
+// vouchedConfigs returns the configurations that are signed by a trusted writer
+// or reported by at least f+1 replicas.
+// A configuration is reported as started only if a signed started copy or f+1 started copies were received.
+func (q qspec) vouchedConfigs(configlists [][]*proto.MetaConfig) []*proto.MetaConfig {
+	type candidate struct {
+		// copies of the configuration, not started and started
+		copies         [2]*proto.MetaConfig
+		count, started int
+		signed         bool
+	}
+	byConfig := make(map[string]*candidate)
+	for _, list := range configlists {
+		seen := make(map[string]bool)
+		for _, c := range list {
+			plain := pb.Clone(c).(*proto.MetaConfig)
+			plain.Started = false
+			id := string(configDigest(plain))
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			cand, ok := byConfig[id]
+			if !ok {
+				cand = &candidate{}
+				byConfig[id] = cand
+			}
+			cand.count++
+			if c.GetStarted() {
+				cand.started++
+				if cand.copies[1] == nil {
+					cand.copies[1] = c
+				}
+			} else if cand.copies[0] == nil {
+				cand.copies[0] = c
+			}
+			if q.keys.verifyConfig(c) {
+				// prefer signed copies
+				cand.signed = true
+				if c.GetStarted() {
+					cand.started = q.faults + 1
+					cand.copies[1] = c
+				} else {
+					cand.copies[0] = c
+				}
+			}
+		}
+	}
+	list := make([]*proto.MetaConfig, 0, len(byConfig))
+	for _, c := range byConfig {
+		if c.count <= q.faults && !c.signed {
+			continue
+		}
+		conf := c.copies[0]
+		if c.started > q.faults {
+			conf = c.copies[1]
+		} else if conf == nil {
+			conf = pb.Clone(c.copies[1]).(*proto.MetaConfig)
+			conf.Started = false
+		}
+		list = append(list, conf)
+	}
+	return list
+}
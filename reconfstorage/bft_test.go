@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// signedReply returns a reply with the value of key signed by k.
+func signedReply(k *keyring, key, value string, sec int64) *proto.ReadResponse {
+	req := &proto.WriteRequest{Key: key, Value: value, Time: timestamppb.New(time.Unix(sec, 0))}
+	k.signWrite(req)
+	return &proto.ReadResponse{OK: true, Value: value, Time: req.GetTime(), Signature: req.GetSignature(), Writer: req.GetWriter()}
+}
+
+// newBFTQSpec returns the quorum specification of a Byzantine system of n servers tolerating f faults,
+// whose client trusts k.
+func newBFTQSpec(t *testing.T, n, f int, k *keyring) *qspec {
+	t.Helper()
+	system, err := newByzantineSystem(n, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newQSpec(n, bindTest(system, n), k, newKnownConfigs())
+}
+
+func TestVouchedValue(t *testing.T) {
+	trusted := newKeyring()
+	untrusted := newKeyring()
+	q := newBFTQSpec(t, 4, 1, trusted)
+
+	tests := []struct {
+		name    string
+		replies map[uint32]*proto.ReadResponse
+		want    string
+	}{
+		{
+			name: "faulty replica reports a newer unsigned value",
+			replies: map[uint32]*proto.ReadResponse{
+				1: readReply("v1", 1), 2: readReply("v1", 1), 3: readReply("forged", 9),
+			},
+			want: "v1",
+		},
+		{
+			name: "faulty replica reports a value signed by an untrusted writer",
+			replies: map[uint32]*proto.ReadResponse{
+				1: readReply("v1", 1), 2: readReply("v1", 1), 3: signedReply(untrusted, "k", "forged", 9),
+			},
+			want: "v1",
+		},
+		{
+			name: "a single replica has the newest signed value",
+			replies: map[uint32]*proto.ReadResponse{
+				1: readReply("v1", 1), 2: readReply("v1", 1), 3: signedReply(trusted, "k", "v2", 2),
+			},
+			want: "v2",
+		},
+		{
+			name: "faulty replica changes a signed value",
+			replies: map[uint32]*proto.ReadResponse{
+				1: signedReply(trusted, "k", "v1", 1), 2: func() *proto.ReadResponse {
+					r := signedReply(trusted, "k", "v2", 2)
+					r.Value = "forged"
+					return r
+				}(),
+			},
+			want: "v1",
+		},
+		{
+			name: "replicas agree on the value but not on the configurations",
+			replies: map[uint32]*proto.ReadResponse{
+				1: func() *proto.ReadResponse {
+					r := readReply("v2", 2)
+					r.ConfigDigest = []byte("client")
+					return r
+				}(),
+				2: func() *proto.ReadResponse {
+					r := readReply("v2", 2)
+					r.MConfigs = benchConfigs(2)
+					return r
+				}(),
+				3: readReply("v1", 1),
+			},
+			want: "v2",
+		},
+		{
+			name: "no value is reported by f+1 replicas",
+			replies: map[uint32]*proto.ReadResponse{
+				1: readReply("v1", 1), 2: readReply("v2", 2),
+			},
+			want: "",
+		},
+	}
+	for _, test := range tests {
+		got := q.vouchedValue("k", test.replies)
+		if got.GetValue() != test.want {
+			t.Errorf("%s: vouchedValue = %q, want %q", test.name, got.GetValue(), test.want)
+		}
+	}
+}
+
+func TestByzantineQuorums(t *testing.T) {
+	// n = 4, f = 1: quorums of 3, which share f+1 = 2 servers
+	system, err := newByzantineSystem(4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(system, 4), []quorumCase{
+		{"two", nodeSet(1, 2), false, false, false},
+		{"three", nodeSet(1, 2, 3), true, true, true},
+	})
+	if _, err := newByzantineSystem(3, 1); err == nil {
+		t.Error("3 servers tolerating 1 fault: got no error")
+	}
+}
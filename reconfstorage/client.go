@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"reconfstorage/proto"
@@ -13,16 +15,70 @@ import (
 	"github.com/relab/gorums"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	pb "google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type client struct {
-	mgr  *proto.Manager
-	cfg  *proto.Configuration
-	pcfg *proto.MetaConfig
+	mgr   *proto.Manager
+	cfg   *proto.Configuration
+	pcfg  *proto.MetaConfig
+	mu    sync.Mutex // protects cfg, pcfg, level, thrifty, hedged and mode
+	retry retryPolicy
+	// level is the default consistency level of operations
+	level proto.Consistency
+	// inflight bounds the number of asynchronous operations
+	inflight chan struct{}
+	// keys signs the values and configurations written by the client
+	keys *keyring
+	// thrifty makes reads and writes contact the fastest quorum first
+	thrifty   bool
+	latencies *latencies
+	// hedged makes reads and writes resend to slow nodes
+	hedged    bool
+	callTimes callTimes
+	stats     callStats
+	// known are the configurations the client has received, so that servers can reply with their digest
+	known *knownConfigs
+	// dialMu serializes the creation of configurations
+	dialMu sync.Mutex
+	// nodeConfigs are the configurations of single nodes, by node ID, see nodeConfiguration
+	nodeConfigs map[uint32]*proto.Configuration
+	// mode selects how reconf moves to the next configuration
+	mode     reconfMode
+	proposer uint32
+	// jobs are the reconfigurations the client runs or watches, see jobs.go
+	jobs       *jobs
+	jobTimeout time.Duration
 }
 
-func newClient(addresses []string) *client {
+// reconfMode selects how reconf moves to the next configuration.
+type reconfMode int
+
+const (
+	// reconfDirect announces the goal as the next configuration.
+	reconfDirect reconfMode = iota
+	// reconfPaxos lets the servers choose the next configuration with Paxos, see paxos.go.
+	reconfPaxos
+	// reconfLattice agrees on the changes of the next configuration with lattice agreement, see lattice.go.
+	reconfLattice
+	// reconfJoint moves through a joint configuration of the old and new servers, see joint.go.
+	reconfJoint
+)
+
+func (m reconfMode) String() string {
+	switch m {
+	case reconfPaxos:
+		return "paxos"
+	case reconfLattice:
+		return "lattice"
+	case reconfJoint:
+		return "joint"
+	}
+	return "direct"
+}
+
+func newClient(addresses []string, keys *keyring) *client {
 	if len(addresses) < 1 {
 		log.Fatalln("No addresses provided!")
 	}
@@ -39,19 +95,88 @@ func newClient(addresses []string) *client {
 	log.Println("Manager created")
 	log.Printf("Adddresses %s\n", addresses)
 	// create configuration containing all nodes
-	cfg, err := mgr.NewConfiguration(&qspec{cfgSize: len(addresses)}, gorums.WithNodeList(addresses))
+	system, _ := newVotingSystem(len(addresses), 0, 0, nil)
+	known := newKnownConfigs()
+	qs := newQSpec(len(addresses), system, keys, known)
+	cfg, err := mgr.NewConfiguration(qs, gorums.WithNodeList(addresses))
 	if err != nil {
 		log.Fatal(err)
 	}
+	bindNodes(qs, cfg, addresses)
 
 	log.Println("Conf created")
 
-	pcfg := &proto.MetaConfig{Adds: "0:" + fmt.Sprint(len(addresses)-1), Time: &timestamppb.Timestamp{Seconds: 1, Nanos: 1}}
+	pcfg := &proto.MetaConfig{Adds: configString(addresses, ""), Time: &timestamppb.Timestamp{Seconds: 1, Nanos: 1}}
+	for _, addr := range addresses {
+		server, err := newServer(addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pcfg.Servers = append(pcfg.Servers, server)
+	}
 
 	return &client{
-		mgr:  mgr,
-		cfg:  cfg,
-		pcfg: pcfg,
+		mgr:   mgr,
+		cfg:   cfg,
+		pcfg:  pcfg,
+		retry: defaultRetryPolicy,
+		keys:  keys,
+
+		latencies: newLatencies(),
+		known:     known,
+		proposer:  rand.Uint32(),
+
+		jobs:       newJobs(),
+		jobTimeout: defaultJobTimeout,
+
+		inflight: make(chan struct{}, maxInflight),
+	}
+}
+
+// current returns the meta-configuration the client currently uses.
+func (c *client) current() *proto.MetaConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pcfg
+}
+
+// consistency returns the default consistency level of the client.
+func (c *client) consistency() proto.Consistency {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level
+}
+
+// setConsistency changes the default consistency level of the client.
+func (c *client) setConsistency(level proto.Consistency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.level = level
+}
+
+// reconfMode returns how reconf moves to the next configuration.
+func (c *client) reconfMode() reconfMode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mode
+}
+
+// setReconfMode changes how reconf moves to the next configuration.
+func (c *client) setReconfMode(mode reconfMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mode = mode
+}
+
+// adopt makes the client use the started configuration conf, if it is newer than the current one.
+func (c *client) adopt(conf *proto.MetaConfig) {
+	if !conf.GetStarted() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if TimeBefore(c.pcfg.GetTime(), conf.GetTime()) {
+		c.pcfg = conf
 	}
 }
 
@@ -65,7 +190,7 @@ func getMin(configs map[string]*proto.MetaConfig) string {
 	min := ""
 
 	for s, config := range configs {
-		if min == "" || config.Time.AsTime().After(configs[min].Time.AsTime()) {
+		if min == "" || config.Time.AsTime().Before(configs[min].Time.AsTime()) {
 			min = s
 		}
 	}
@@ -73,10 +198,11 @@ func getMin(configs map[string]*proto.MetaConfig) string {
 }
 
 // addConfigs checks newconfigs for once that are newer than cur
-// newer confgs are added to the confmap
+// newer confgs are added to the confmap, keyed by configKey, so that configurations of the lattice with the same timestamp are all kept
 // if a newer started configuration is found, this is the only returned function
 // and the client state is updated
 func (c *client) addConfigs(confmap map[string]*proto.MetaConfig, cur *proto.MetaConfig, newconfigs []*proto.MetaConfig) map[string]*proto.MetaConfig {
+	c.known.learn(newconfigs)
 	for _, cc := range newconfigs {
 		if TimeBefore(cur.GetTime(), cc.GetTime()) {
 			if cc.GetStarted() {
@@ -84,10 +210,13 @@ func (c *client) addConfigs(confmap map[string]*proto.MetaConfig, cur *proto.Met
 				confmap = make(map[string]*proto.MetaConfig, 1)
 
 				//update client state
-				c.pcfg = cc
+				c.adopt(cc)
 
+			} else {
+				// take over the reconfiguration if it makes no progress
+				c.watch(cc)
 			}
-			confmap[cc.GetTime().String()] = cc
+			confmap[configKey(cc)] = cc
 		}
 	}
 	return confmap
@@ -97,17 +226,30 @@ func TimeBefore(a, b *timestamppb.Timestamp) bool {
 	return a.AsTime().Before(b.AsTime())
 }
 
-func (c *client) read(key string) *proto.ReadResponse {
-	confmap := map[string]*proto.MetaConfig{c.pcfg.Time.String(): c.pcfg}
+func (c *client) read(key string, level proto.Consistency) (*proto.ReadResponse, error) {
+	return c.readFrom(key, level, c.current(), nil)
+}
+
+// readFrom reads key from the configuration start and all its successors.
+// If first is not nil, it is used as the reply of start instead of calling readAt.
+func (c *client) readFrom(key string, level proto.Consistency, start *proto.MetaConfig, first *proto.ReadResponse) (*proto.ReadResponse, error) {
+	confmap := map[string]*proto.MetaConfig{configKey(start): start}
 	resp := &proto.ReadResponse{Time: &timestamppb.Timestamp{Seconds: 0, Nanos: 0}}
 
-	for min := getMin(confmap); len(confmap) > 0; {
-		cfg, err := c.parseConfiguration(confmap[min].Adds)
-		if err != nil {
-			log.Println(err.Error())
-			continue
+	for len(confmap) > 0 {
+		min := getMin(confmap)
+		minresp := first
+		first = nil
+		if minresp == nil {
+			cfg, err := c.parseConfiguration(confmap[min])
+			if err != nil {
+				return nil, err
+			}
+			minresp, err = c.readAt(key, level, confmap[min], cfg)
+			if err != nil {
+				return nil, err
+			}
 		}
-		minresp := c.readQC(key, cfg)
 
 		// remember Value, if it has larger Time
 		if TimeBefore(resp.GetTime(), minresp.GetTime()) {
@@ -117,50 +259,94 @@ func (c *client) read(key string) *proto.ReadResponse {
 		confmap = c.addConfigs(confmap, confmap[min], minresp.GetMConfigs())
 		delete(confmap, min)
 	}
-	return resp
+	return resp, nil
 }
 
-func (client) readQC(key string, cfg *proto.Configuration) *proto.ReadResponse {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	resp, err := cfg.ReadQC(ctx, &proto.ReadRequest{Key: key})
-	cancel()
+func (c *client) readQC(key string, level proto.Consistency, conf *proto.MetaConfig, cfg *proto.Configuration) (resp *proto.ReadResponse, err error) {
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		c.stats.add(cfg.Size(), false)
+		resp, err = cfg.ReadQC(ctx, &proto.ReadRequest{Key: key, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()})
+		return quorumError("ReadQC", err)
+	})
 	if err != nil {
-		fmt.Printf("Read RPC finished with error: %v\n", err)
-		return nil
+		return nil, err
 	}
-	return resp
+	return resp, nil
 }
 
-func (c *client) write(key, value string) *proto.WriteResponse {
+// write performs the write on the current configuration and all its successors.
+// The same timestamp is used in every configuration.
+func (c *client) write(key, value string, level proto.Consistency) (*proto.WriteResponse, error) {
+	return c.writeFrom(key, value, timestamppb.Now(), level, c.current(), nil)
+}
 
-	// TODO perform the write on c.cfg and all its successors
+// writeFrom writes the value with timestamp ts to the configuration start and all its successors.
+// If first is not nil, it is used as the reply of start instead of calling writeAt.
+func (c *client) writeFrom(key, value string, ts *timestamppb.Timestamp, level proto.Consistency, start *proto.MetaConfig, first *proto.WriteResponse) (*proto.WriteResponse, error) {
+	confmap := map[string]*proto.MetaConfig{configKey(start): start}
+
+	for len(confmap) > 0 {
+		min := getMin(confmap)
+		minresp := first
+		first = nil
+		if minresp == nil {
+			cfg, err := c.parseConfiguration(confmap[min])
+			if err != nil {
+				return nil, err
+			}
+			minresp, err = c.writeAt(key, value, ts, level, confmap[min], cfg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// a fenced write continues on the newer configuration in MConfigs
+		if !minresp.GetNew() && !minresp.GetFenced() {
+			return nil, fmt.Errorf("write %q: %w", key, ErrStaleTimestamp)
+		}
+
+		confmap = c.addConfigs(confmap, confmap[min], minresp.GetMConfigs())
+		delete(confmap, min)
+	}
 
-	return &proto.WriteResponse{New: false}
+	return &proto.WriteResponse{New: true}, nil
 }
 
-func (client) writeQC(key, value string, cfg *proto.Configuration) *proto.WriteResponse {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	resp, err := cfg.WriteQC(ctx, &proto.WriteRequest{Key: key, Value: value, Time: timestamppb.Now()})
-	cancel()
+// writeQC writes the value with timestamp ts to cfg.
+// Retries reuse ts, so that a repeated write does not overwrite a newer value.
+func (c *client) writeQC(key, value string, ts *timestamppb.Timestamp, level proto.Consistency, conf *proto.MetaConfig, cfg *proto.Configuration) (resp *proto.WriteResponse, err error) {
+	req := &proto.WriteRequest{Key: key, Value: value, Time: ts, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()}
+	c.keys.signWrite(req)
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		c.stats.add(cfg.Size(), false)
+		resp, err = cfg.WriteQC(ctx, req)
+		return quorumError("WriteQC", err)
+	})
 	if err != nil {
-		fmt.Printf("Write RPC finished with error: %v\n", err)
-		return nil
+		return nil, err
 	}
-	return resp
+	return resp, nil
 }
 
-func (c *client) list() *proto.ListResponse {
-	confmap := map[string]*proto.MetaConfig{c.pcfg.Time.String(): c.pcfg}
+func (c *client) list(level proto.Consistency) (*proto.ListResponse, error) {
+	start := c.current()
+	confmap := map[string]*proto.MetaConfig{configKey(start): start}
 
 	var keys map[string]bool
 
-	for min := getMin(confmap); len(confmap) > 0; {
-		cfg, err := c.parseConfiguration(confmap[min].Adds)
+	for len(confmap) > 0 {
+		min := getMin(confmap)
+		cfg, err := c.parseConfiguration(confmap[min])
+		if err != nil {
+			return nil, err
+		}
+		minresp, err := c.listQC(level, confmap[min], cfg)
 		if err != nil {
-			log.Println(err.Error())
-			continue
+			return nil, err
 		}
-		minresp := c.listQC(cfg)
 
 		if len(keys) == 0 {
 			keys = make(map[string]bool, len(minresp.GetKeys()))
@@ -179,136 +365,344 @@ func (c *client) list() *proto.ListResponse {
 		allkeys = append(allkeys, k)
 	}
 
-	return &proto.ListResponse{Keys: allkeys}
+	return &proto.ListResponse{Keys: allkeys}, nil
 }
 
-func (client) listQC(cfg *proto.Configuration) *proto.ListResponse {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	resp, err := cfg.ListKeysQC(ctx, &proto.ListRequest{})
-	cancel()
+func (c *client) listQC(level proto.Consistency, conf *proto.MetaConfig, cfg *proto.Configuration) (resp *proto.ListResponse, err error) {
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err = cfg.ListKeysQC(ctx, &proto.ListRequest{Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()})
+		return quorumError("ListKeysQC", err)
+	})
 	if err != nil {
-		fmt.Printf("ListKeys RPC finished with error: %v\n", err)
-		return nil
+		return nil, err
 	}
-	return resp
+	return resp, nil
 }
 
-func (c *client) writeConfig(target *proto.MetaConfig) *proto.WriteResponse {
-	confmap := map[string]*proto.MetaConfig{c.pcfg.Time.String(): c.pcfg}
+// writeConfig announces target to the current configuration and all its successors,
+// and returns the configurations it was announced to, in the order of their timestamps, in MConfigs.
+func (c *client) writeConfig(target *proto.MetaConfig) (*proto.WriteResponse, error) {
+	target = c.keys.signConfig(target)
+	start := c.current()
+	confmap := map[string]*proto.MetaConfig{configKey(start): start}
+	var announced []*proto.MetaConfig
 
-	for min := getMin(confmap); len(confmap) > 0; {
+	for len(confmap) > 0 {
+		min := getMin(confmap)
+		announced = append(announced, confmap[min])
 
 		if target.GetTime().AsTime().Before(confmap[min].GetTime().AsTime()) {
-			return &proto.WriteResponse{New: false}
+			return nil, fmt.Errorf("write config %q: %w", target.GetAdds(), ErrConfigSuperseded)
 		}
 
-		cfg, err := c.parseConfiguration(confmap[min].Adds)
+		cfg, err := c.parseConfiguration(confmap[min])
 		if err != nil {
-			log.Println(err.Error())
-			continue
+			return nil, err
+		}
+		minresp, err := c.writeConfigQC(target, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if !minresp.GetNew() {
+			return nil, fmt.Errorf("write config %q: %w", target.GetAdds(), ErrConfigSuperseded)
 		}
-		minresp := c.writeConfigQC(target, cfg)
 
 		confmap = c.addConfigs(confmap, confmap[min], minresp.GetMConfigs())
 		delete(confmap, min)
 
 	}
 
-	return &proto.WriteResponse{New: true}
+	return &proto.WriteResponse{New: true, MConfigs: announced}, nil
 }
 
-func (client) writeConfigQC(conf *proto.MetaConfig, cfg *proto.Configuration) *proto.WriteResponse {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	resp, err := cfg.WriteMetaConfQC(ctx, conf)
-	cancel()
+func (c *client) writeConfigQC(conf *proto.MetaConfig, cfg *proto.Configuration) (resp *proto.WriteResponse, err error) {
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err = cfg.WriteMetaConfQC(ctx, conf)
+		return quorumError("WriteMetaConfQC", err)
+	})
 	if err != nil {
-		fmt.Printf("ListKeys RPC finished with error: %v\n", err)
-		return nil
+		return nil, err
 	}
-	return resp
+	return resp, nil
 }
 
-func (c *client) reconf(newAdds string) {
+// reconf moves the storage to the configuration goal.
+// The new configuration is announced to the current configuration and its successors,
+// the state is copied from the old configurations to the new one,
+// and finally the new configuration is marked as started and the servers it removed are decommissioned.
+// The servers of the new configuration pull the state from read quorums of the old configurations,
+// until a write quorum of them has it, see transfer.go.
+func (c *client) reconf(goal *proto.MetaConfig) error {
+	goal, err := c.resolve(goal)
+	if err != nil {
+		return err
+	}
+	switch c.reconfMode() {
+	case reconfPaxos:
+		return c.reconfOrdered(goal)
+	case reconfLattice:
+		return c.reconfLattice(goal)
+	case reconfJoint:
+		return c.reconfJoint(goal)
+	}
 
-	goalProtoConf := &proto.MetaConfig{Adds: newAdds, Started: false, Time: timestamppb.Now()}
+	goalProtoConf := pb.Clone(goal).(*proto.MetaConfig)
+	goalProtoConf.Started = false
+	goalProtoConf.Time = timestampAfter(c.current().GetTime())
+	return c.install(goalProtoConf)
+}
+
+// install announces, fills and starts the configuration goalProtoConf, see reconf.
+// Installing a configuration again, e.g. by several clients, has no further effect.
+func (c *client) install(goalProtoConf *proto.MetaConfig) error {
+	if c.jobs.claim(goalProtoConf) {
+		defer c.jobs.release(goalProtoConf)
+	}
 	// create a Configuration used for quorum calls.
-	goalCfg, err := c.parseConfiguration(newAdds)
+	goalCfg, err := c.parseConfiguration(goalProtoConf)
 	if err != nil {
-		log.Printf("Could not create new configuration: %s", newAdds)
-		return
+		return err
+	}
+
+	// inform the old configurations about the new configuration;
+	// this is their stop sign, after which their servers reject writes and point clients at the new configuration
+	announced, err := c.writeConfig(goalProtoConf)
+	if err != nil {
+		return fmt.Errorf("announce configuration: %w", err)
+	}
+
+	// record the announcement on the new configuration, and continue where another client left off
+	job, err := c.resumeJob(goalProtoConf, goalCfg)
+	if err != nil {
+		return fmt.Errorf("announce configuration: %w", err)
 	}
 
-	// TODO: perform reconfiguration
+	// copy the state from the old configurations that the configuration was announced to
+	var sources []*proto.MetaConfig
+	for _, conf := range announced.GetMConfigs() {
+		if TimeBefore(conf.GetTime(), goalProtoConf.GetTime()) {
+			sources = append(sources, conf)
+		}
+	}
+	if err := c.transferState(job, sources, goalProtoConf, goalCfg); err != nil {
+		return fmt.Errorf("state transfer: %w", err)
+	}
 
+	// start the new configuration
+	goalProtoConf = pb.Clone(goalProtoConf).(*proto.MetaConfig)
 	goalProtoConf.Started = true
+	if _, err := c.writeConfig(goalProtoConf); err != nil {
+		return fmt.Errorf("start configuration: %w", err)
+	}
 
 	// update the default configuration used by the client
+	c.mu.Lock()
 	c.cfg = goalCfg
 	c.pcfg = goalProtoConf
+	c.mu.Unlock()
+
+	// the configuration is started even if the progress cannot be recorded
+	_ = job.start()
+	// the servers that were removed are no longer needed
+	c.decommission(sources, goalProtoConf)
+	return nil
 }
 
-func (c client) parseConfiguration(cfgStr string) (cfg *proto.Configuration, err error) {
-	// configuration using range syntax
-	if i := strings.Index(cfgStr, ":"); i > -1 {
-		var start, stop int
-		var err error
-		numNodes := c.mgr.Size()
-		if i == 0 {
-			start = 0
-		} else {
-			start, err = strconv.Atoi(cfgStr[:i])
+// parseConfiguration creates a Configuration with the servers of conf
+// and a quorum specification using the quorum system named in conf.Adds,
+// with the read and write quorum sizes and vote weights of conf.
+func (c *client) parseConfiguration(conf *proto.MetaConfig) (cfg *proto.Configuration, err error) {
+	cfg, _, err = c.newConfiguration(conf)
+	return cfg, err
+}
+
+// newConfiguration is like parseConfiguration, but also returns the quorum specification,
+// which can be used to create configurations of a subset of the nodes.
+func (c *client) newConfiguration(conf *proto.MetaConfig) (*proto.Configuration, *qspec, error) {
+	system, nodes, err := c.parseQuorumSystem(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	qs := newQSpec(len(nodes), system, c.keys, c.known)
+	cfg, err := c.createConfiguration(qs, gorums.WithNodeList(nodes))
+	if err != nil {
+		return nil, nil, &ConfigError{Config: conf.GetAdds(), Cause: err}
+	}
+	bindNodes(qs, cfg, nodes)
+	return cfg, qs, nil
+}
+
+// createConfiguration creates a configuration of the nodes given by opt.
+// Calls are serialized, since creating a configuration may dial servers and add them to the manager.
+func (c *client) createConfiguration(qs *qspec, opt gorums.NodeListOption) (*proto.Configuration, error) {
+	c.dialMu.Lock()
+	defer c.dialMu.Unlock()
+	return c.mgr.NewConfiguration(qs, opt)
+}
+
+// parseQuorumSystem returns the quorum system of conf and the addresses of its servers, in order.
+// The servers are taken from conf.Servers, or if it is empty, from the index shorthand in conf.Adds.
+func (c *client) parseQuorumSystem(conf *proto.MetaConfig) (quorumSystem, []string, error) {
+	if isJoint(conf) {
+		return c.parseJointSystem(conf)
+	}
+	nodesStr, systemStr := splitConfig(conf.GetAdds())
+	var nodes []string
+	if servers := conf.GetServers(); len(servers) > 0 {
+		nodes = make([]string, len(servers))
+		for i, s := range servers {
+			server, err := newServer(s.GetAddress())
 			if err != nil {
-				fmt.Printf("Failed to parse configuration: %v\n", err)
-				return nil, err
+				return nil, nil, &ConfigError{Config: conf.GetAdds(), Cause: err}
 			}
-		}
-		if i == len(cfgStr)-1 {
-			stop = numNodes
-		} else {
-			stop, err = strconv.Atoi(cfgStr[i+1:])
-			if err != nil {
-				fmt.Printf("Failed to parse configuration: %v\n", err)
-				return nil, err
+			if server.GetID() != s.GetID() {
+				return nil, nil, &ConfigError{Config: conf.GetAdds(), Cause: fmt.Errorf("server %s has ID %d, not %d", s.GetAddress(), server.GetID(), s.GetID())}
 			}
+			nodes[i] = server.GetAddress()
+		}
+	} else {
+		var err error
+		nodes, err = c.parseNodes(nodesStr)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	system, err := newQuorumSystem(systemStr, len(nodes), conf)
+	if err != nil {
+		return nil, nil, &ConfigError{Config: conf.GetAdds(), Cause: err}
+	}
+	return system, nodes, nil
+}
+
+// resolve returns conf with its servers given by address and ID in Servers,
+// so that any client can use it, no matter in which order it lists the servers.
+// The index shorthand in conf.Adds is resolved with the servers of this client,
+// and replaced by the addresses of the servers.
+func (c *client) resolve(conf *proto.MetaConfig) (*proto.MetaConfig, error) {
+	if len(conf.GetServers()) > 0 {
+		return conf, nil
+	}
+	nodesStr, systemStr := splitConfig(conf.GetAdds())
+	nodes, err := c.parseNodes(nodesStr)
+	if err != nil {
+		return nil, err
+	}
+	resolved := pb.Clone(conf).(*proto.MetaConfig)
+	resolved.Servers = make([]*proto.Server, 0, len(nodes))
+	for _, addr := range nodes {
+		server, err := newServer(addr)
+		if err != nil {
+			return nil, &ConfigError{Config: conf.GetAdds(), Cause: err}
+		}
+		resolved.Servers = append(resolved.Servers, server)
+	}
+	resolved.Adds = configString(nodes, systemStr)
+	return resolved, nil
+}
+
+// newServer returns the server with address addr and the node ID that Gorums derives from the address.
+func newServer(addr string) (*proto.Server, error) {
+	node, err := gorums.NewRawNode(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.Server{ID: node.ID(), Address: node.Address()}, nil
+}
+
+// configString returns the configuration string of the servers addrs and the quorum system spec.
+func configString(addrs []string, system string) string {
+	if system == "" {
+		return strings.Join(addrs, ",")
+	}
+	return strings.Join(addrs, ",") + "@" + system
+}
+
+// bindNodes binds the quorum specification and its quorum system to the IDs of the nodes of cfg, given in the order of addrs.
+func bindNodes(qs *qspec, cfg *proto.Configuration, addrs []string) {
+	byAddr := make(map[string]uint32, len(addrs))
+	for _, n := range cfg.Nodes() {
+		byAddr[n.Address()] = n.ID()
+	}
+	ids := make([]uint32, len(addrs))
+	for i, addr := range addrs {
+		ids[i] = byAddr[addr]
+	}
+	qs.ids = ids
+	qs.system.bind(ids)
+}
+
+// parseNodes returns the addresses of the servers in the configuration string cfgStr:
+// a range of indices on the client, or a list of indices and addresses.
+// Servers given by an address do not have to be known to the client;
+// they are dialed when a configuration with them is created.
+func (c *client) parseNodes(cfgStr string) ([]string, error) {
+	// configuration using range syntax
+	if isRange(cfgStr) {
+		first, last, _ := strings.Cut(cfgStr, ":")
+		numNodes := c.mgr.Size()
+		// isRange has checked that the indices given are integers
+		start, stop := 0, numNodes
+		if first != "" {
+			start, _ = strconv.Atoi(first)
+		}
+		if last != "" {
+			stop, _ = strconv.Atoi(last)
 		}
 		if start >= stop || start < 0 || stop > numNodes {
-			fmt.Println("Invalid configuration.")
-			return nil, fmt.Errorf("could not parse configuration: %s", cfgStr)
+			return nil, &ConfigError{Config: cfgStr, Cause: fmt.Errorf("range must be within 0:%d", numNodes)}
 		}
 		nodes := make([]string, 0)
 		for _, node := range c.mgr.Nodes()[start:stop] {
 			nodes = append(nodes, node.Address())
 		}
-		cfg, err = c.mgr.NewConfiguration(&qspec{cfgSize: stop - start}, gorums.WithNodeList(nodes))
-		if err != nil {
-			fmt.Printf("Failed to create configuration: %v\n", err)
-			return nil, err
-		}
-		return cfg, nil
+		return nodes, nil
 	}
-	// configuration using list of indices
+	// configuration using list of indices and addresses
 	if indices := strings.Split(cfgStr, ","); len(indices) > 0 {
 		selectedNodes := make([]string, 0, len(indices))
 		nodes := c.mgr.Nodes()
 		for _, index := range indices {
+			if isRange(index) {
+				return nil, &ConfigError{Config: cfgStr, Cause: fmt.Errorf("range %s in a list", index)}
+			}
+			if strings.Contains(index, ":") {
+				server, err := newServer(index)
+				if err != nil {
+					return nil, &ConfigError{Config: cfgStr, Cause: err}
+				}
+				selectedNodes = append(selectedNodes, server.GetAddress())
+				continue
+			}
 			i, err := strconv.Atoi(index)
 			if err != nil {
-				fmt.Printf("Failed to parse configuration: %v\n", err)
-				return nil, err
+				return nil, &ConfigError{Config: cfgStr, Cause: err}
 			}
 			if i < 0 || i >= len(nodes) {
-				fmt.Println("Invalid configuration.")
-				return nil, fmt.Errorf("could not parse configuration: %s", cfgStr)
+				return nil, &ConfigError{Config: cfgStr, Cause: fmt.Errorf("index %d out of range", i)}
 			}
 			selectedNodes = append(selectedNodes, nodes[i].Address())
 		}
-		cfg, err := c.mgr.NewConfiguration(&qspec{cfgSize: len(selectedNodes)}, gorums.WithNodeList(selectedNodes))
-		if err != nil {
-			fmt.Printf("Failed to create configuration: %v\n", err)
-			return nil, err
+		return selectedNodes, nil
+	}
+	return nil, &ConfigError{Config: cfgStr}
+}
+
+// isRange reports whether cfgStr uses the range syntax '[start]:[stop]' with indices on the client,
+// rather than being the address of a server. Either index may be left out, and defaults to 0 or
+// the number of nodes; an address needs both a host and a port, so '2:' and ':3' are ranges.
+func isRange(cfgStr string) bool {
+	start, stop, ok := strings.Cut(cfgStr, ":")
+	if !ok || start == "" && stop == "" {
+		return false
+	}
+	for _, s := range []string{start, stop} {
+		if _, err := strconv.Atoi(s); s != "" && err != nil {
+			return false
 		}
-		return cfg, nil
 	}
-	fmt.Println("Invalid configuration.")
-	return nil, fmt.Errorf("could not parse configuration: %s", cfgStr)
+	return true
 }
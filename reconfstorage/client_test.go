@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestIsRange(t *testing.T) {
+	tests := []struct {
+		cfgStr string
+		want   bool
+	}{
+		{"1:4", true},
+		{"0:10", true},
+		{"2:", true},
+		{":3", true},
+		{":8080", true},
+		{"localhost:8080", false},
+		{"127.0.0.1:8080", false},
+		{"localhost:", false},
+		{":", false},
+		{"3", false},
+	}
+	for _, test := range tests {
+		if got := isRange(test.cfgStr); got != test.want {
+			t.Errorf("isRange(%q) = %v, want %v", test.cfgStr, got, test.want)
+		}
+	}
+}
+
+func TestParseNodesOpenRange(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	// indices refer to the nodes in the order of the manager
+	var addrs []string
+	for _, n := range c.mgr.Nodes() {
+		addrs = append(addrs, n.Address())
+	}
+	tests := []struct {
+		cfgStr string
+		want   []string
+	}{
+		{"1:", addrs[1:]},
+		{":2", addrs[:2]},
+		{"0:3", addrs},
+	}
+	for _, test := range tests {
+		got, err := c.parseNodes(test.cfgStr)
+		if err != nil {
+			t.Errorf("parseNodes(%q): %v", test.cfgStr, err)
+			continue
+		}
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("parseNodes(%q) = %v, want %v", test.cfgStr, got, test.want)
+		}
+	}
+	for _, cfgStr := range []string{":8080", "3:"} {
+		if _, err := c.parseNodes(cfgStr); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("parseNodes(%q): err = %v, want %v", cfgStr, err, ErrInvalidConfig)
+		}
+	}
+}
+
+// TestFollowNewConfiguration reconfigures three servers to two of them and a new one,
+// and then uses the old configuration from a client that has not learned of the reconfiguration.
+func TestFollowNewConfiguration(t *testing.T) {
+	c, addrs := newTestCluster(t, 3)
+	srv, extra := startServer("127.0.0.1:0")
+	t.Cleanup(srv.Stop)
+	stale := newClient(addrs, newKeyring())
+	old := stale.current()
+
+	if err := c.reconf(&proto.MetaConfig{Adds: configString([]string{addrs[1], addrs[2], extra}, "")}); err != nil {
+		t.Fatal(err)
+	}
+	next := c.current()
+	if !next.GetStarted() || !TimeBefore(old.GetTime(), next.GetTime()) {
+		t.Fatalf("current configuration %v, want a started successor of %v", next, old)
+	}
+
+	// the old servers reject the write and point at the new configuration
+	cfg, err := stale.parseConfiguration(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stale.writeQC("k", "old", timestamppb.Now(), proto.Consistency_MAJORITY, old, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.GetFenced() || resp.GetNew() {
+		t.Errorf("write to the old configuration: %v, want fenced", resp)
+	}
+	pointed := false
+	for _, conf := range resp.GetMConfigs() {
+		pointed = pointed || configKey(conf) == configKey(next)
+	}
+	if !pointed {
+		t.Errorf("fenced write points at %v, want %v", resp.GetMConfigs(), next)
+	}
+
+	// a write started on the old configuration completes on the new one, which the client adopts
+	if _, err := stale.writeFrom("k", "v", timestamppb.Now(), proto.Consistency_MAJORITY, old, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := stale.current(); configKey(got) != configKey(next) {
+		t.Errorf("stale client is on %v after the write, want %v", got.GetAdds(), next.GetAdds())
+	}
+	if r, err := c.read("k", proto.Consistency_MAJORITY); err != nil || r.GetValue() != "v" {
+		t.Errorf("read from the new configuration = %v, %v; want v", r.GetValue(), err)
+	}
+
+	// reads of the old configuration are fenced as well, and are answered by the new one
+	if r, err := stale.readQC("k", proto.Consistency_MAJORITY, old, cfg); err != nil || !r.GetFenced() {
+		t.Errorf("read of the old configuration = %v, %v; want fenced", r, err)
+	}
+	if r, err := stale.readFrom("k", proto.Consistency_MAJORITY, old, nil); err != nil || r.GetValue() != "v" {
+		t.Errorf("read from the old configuration = %v, %v; want v", r.GetValue(), err)
+	}
+}
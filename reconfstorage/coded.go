@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// isCoded reports whether the configuration conf stores erasure-coded values.
+func isCoded(conf *proto.MetaConfig) bool {
+	_, system := splitConfig(conf.GetAdds())
+	return strings.HasPrefix(system, "ec:")
+}
+
+// readAt reads key from the configuration conf, from which cfg was created.
+func (c *client) readAt(key string, level proto.Consistency, conf *proto.MetaConfig, cfg *proto.Configuration) (*proto.ReadResponse, error) {
+	if isCoded(conf) {
+		return c.readCoded(key, conf, cfg)
+	}
+	if c.isThrifty() {
+		return c.readThrifty(key, level, conf)
+	}
+	if c.isHedged() {
+		return c.readHedged(key, level, conf)
+	}
+	return c.readQC(key, level, conf, cfg)
+}
+
+// writeAt writes the value with timestamp ts to the configuration conf, from which cfg was created.
+func (c *client) writeAt(key, value string, ts *timestamppb.Timestamp, level proto.Consistency, conf *proto.MetaConfig, cfg *proto.Configuration) (*proto.WriteResponse, error) {
+	if isCoded(conf) {
+		return c.writeCoded(key, value, ts, conf, cfg)
+	}
+	if c.isThrifty() {
+		return c.writeThrifty(key, value, ts, level, conf)
+	}
+	if c.isHedged() {
+		return c.writeHedged(key, value, ts, level, conf)
+	}
+	return c.writeQC(key, value, ts, level, conf, cfg)
+}
+
+// writeCoded splits the value into one fragment per server of conf and writes it in two phases:
+// the fragments are pre-written to a quorum, and then the timestamp is finalized on a quorum.
+// Consistency levels do not apply; both phases use the quorums of the configuration.
+func (c *client) writeCoded(key, value string, ts *timestamppb.Timestamp, conf *proto.MetaConfig, cfg *proto.Configuration) (resp *proto.WriteResponse, err error) {
+	system, nodes, err := c.parseQuorumSystem(conf)
+	if err != nil {
+		return nil, err
+	}
+	k := dataFragments(system)
+	frags, err := encode([]byte(value), len(nodes), k)
+	if err != nil {
+		return nil, err
+	}
+	// the fragment of each node is given by the node's position in the configuration
+	byAddr := make(map[string]int, len(nodes))
+	for i, addr := range nodes {
+		byAddr[addr] = i
+	}
+	pos := make(map[uint32]int, len(nodes))
+	for _, n := range cfg.Nodes() {
+		pos[n.ID()] = byAddr[n.Address()]
+	}
+	fragment := func(req *proto.WriteRequest, id uint32) *proto.WriteRequest {
+		f := frags[pos[id]]
+		return &proto.WriteRequest{Key: req.GetKey(), Time: req.GetTime(), ConfigDigest: req.GetConfigDigest(), ConfigTime: req.GetConfigTime(), Fragment: &proto.Fragment{
+			Index:  uint32(f.index),
+			Total:  uint32(len(nodes)),
+			Needed: uint32(k),
+			Size:   uint32(len(value)),
+			Data:   f.data,
+		}}
+	}
+
+	req := &proto.WriteRequest{Key: key, Time: ts, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()}
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err = cfg.PreWriteQC(ctx, req, fragment)
+		return quorumError("PreWriteQC", err)
+	})
+	if err != nil || !resp.GetNew() {
+		// a fenced reply is followed to the newer configuration by writeFrom
+		return resp, err
+	}
+	var final *proto.WriteResponse
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		final, err = cfg.FinalizeQC(ctx, req)
+		return quorumError("FinalizeQC", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if final.GetFenced() {
+		return final, nil
+	}
+	return resp, nil
+}
+
+// readCoded reads an erasure-coded value in two phases:
+// the newest finalized timestamp is read from a quorum,
+// and then the fragments of that timestamp are collected and decoded.
+// If the fragments were replaced by a newer write in the meantime, the read is retried.
+// A fenced reply is returned as is, so that readFrom follows the newer configuration.
+func (c *client) readCoded(key string, conf *proto.MetaConfig, cfg *proto.Configuration) (resp *proto.ReadResponse, err error) {
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err = cfg.ReadFragmentQC(ctx, &proto.ReadRequest{Key: key, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()})
+		if err != nil {
+			return quorumError("ReadFragmentQC", err)
+		}
+		if !resp.GetOK() {
+			// the key was never written, or the configuration is superseded
+			return nil
+		}
+		resp, err = cfg.ReadFragmentQC(ctx, &proto.ReadRequest{Key: key, Time: resp.GetTime(), ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()})
+		if err != nil {
+			return quorumError("ReadFragmentQC", err)
+		}
+		if resp.GetFenced() {
+			return nil
+		}
+		if !resp.GetOK() {
+			return fmt.Errorf("read %q: not enough fragments: %w", key, ErrQuorumNotReached)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
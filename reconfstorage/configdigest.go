@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"reconfstorage/proto"
+
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// knownLists is the number of lists of configurations a client remembers by their digest.
+const knownLists = 64
+
+// configListDigest returns a digest of a list of configurations that does not depend on their order.
+func configListDigest(configs []*proto.MetaConfig) []byte {
+	encoded := make([]string, 0, len(configs))
+	for _, c := range configs {
+		b, _ := pb.MarshalOptions{Deterministic: true}.Marshal(c)
+		encoded = append(encoded, string(b))
+	}
+	sort.Strings(encoded)
+	h := sha256.New()
+	var n [binary.MaxVarintLen64]byte
+	for _, e := range encoded {
+		h.Write(n[:binary.PutUvarint(n[:], uint64(len(e)))])
+		h.Write([]byte(e))
+	}
+	return h.Sum(nil)
+}
+
+// knownConfigs remembers the lists of configurations a client has received, by their digest.
+// Requests carry the digest of the newest list, and servers that know the same configurations
+// reply with the digest instead of the list. Since replies may arrive after the client has learned
+// a newer list, the most recent knownLists lists are kept.
+type knownConfigs struct {
+	mu      sync.Mutex
+	current []byte
+	lists   map[string][]*proto.MetaConfig
+	order   []string
+}
+
+func newKnownConfigs() *knownConfigs {
+	k := &knownConfigs{lists: make(map[string][]*proto.MetaConfig)}
+	k.learn(nil)
+	return k
+}
+
+// learn makes configs the newest list of configurations known to the client.
+func (k *knownConfigs) learn(configs []*proto.MetaConfig) {
+	digest := configListDigest(configs)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.current = digest
+	if _, ok := k.lists[string(digest)]; ok {
+		// keep the list as the most recent one
+		for i, d := range k.order {
+			if d == string(digest) {
+				k.order = append(k.order[:i], k.order[i+1:]...)
+				break
+			}
+		}
+		k.order = append(k.order, string(digest))
+		return
+	}
+	if len(k.order) == knownLists {
+		delete(k.lists, k.order[0])
+		k.order = k.order[1:]
+	}
+	k.lists[string(digest)] = configs
+	k.order = append(k.order, string(digest))
+}
+
+// digest returns the digest of the newest list of configurations, to be sent in requests.
+func (k *knownConfigs) digest() []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.current
+}
+
+// lookup returns the list of configurations with the given digest, if it is remembered.
+// If the list has been evicted, the client asks for full lists from now on, see forget.
+func (k *knownConfigs) lookup(digest []byte) ([]*proto.MetaConfig, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	configs, ok := k.lists[string(digest)]
+	if !ok {
+		k.forget()
+	}
+	return configs, ok
+}
+
+// forget clears the digest sent in requests, so that servers reply with their full list of
+// configurations until the client learns a new list. The caller must hold k.mu.
+func (k *knownConfigs) forget() {
+	k.current = nil
+}
+
+// configsFor returns the configurations to send in a reply to a request with the given digest,
+// for an operation on the configuration with timestamp ts. It returns none and the digest,
+// so that the client uses its own list, if the client knows the same configurations as the server
+// or the server knows no configuration that is newer than ts. Otherwise, the server knows something
+// the client must follow, and it returns all its configurations.
+// The caller must hold s.mut.
+func (s *storageServer) configsFor(digest []byte, ts *timestamppb.Timestamp) ([]*proto.MetaConfig, []byte) {
+	if len(digest) > 0 && (string(digest) == string(s.digest) || ts != nil && !s.stopped(ts)) {
+		return nil, digest
+	}
+	return s.configs, nil
+}
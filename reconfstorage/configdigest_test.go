@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// benchConfigs returns n configurations, the last of which is started.
+func benchConfigs(n int) []*proto.MetaConfig {
+	configs := make([]*proto.MetaConfig, n)
+	for i := range configs {
+		configs[i] = &proto.MetaConfig{
+			Adds:    fmt.Sprintf("%d:%d", i, i+5),
+			Time:    timestamppb.New(time.Unix(int64(i+1), 0)),
+			Started: i == n-1,
+		}
+	}
+	return configs
+}
+
+func TestConfigListDigest(t *testing.T) {
+	configs := benchConfigs(3)
+	reversed := []*proto.MetaConfig{configs[2], configs[1], configs[0]}
+	if string(configListDigest(configs)) != string(configListDigest(reversed)) {
+		t.Error("the digest depends on the order of the configurations")
+	}
+	started := pb.Clone(configs[1]).(*proto.MetaConfig)
+	started.Started = true
+	changed := []*proto.MetaConfig{configs[0], started, configs[2]}
+	if string(configListDigest(configs)) == string(configListDigest(changed)) {
+		t.Error("the digest does not change when a configuration is started")
+	}
+	if string(configListDigest(configs)) == string(configListDigest(configs[:2])) {
+		t.Error("the digest does not change when a configuration is added")
+	}
+}
+
+func TestConfigsFor(t *testing.T) {
+	configs := benchConfigs(3)
+	s := newStorageServer()
+	s.configs = configs
+	s.digest = configListDigest(configs)
+	older := configListDigest(configs[:2])
+	newest := configs[2].GetTime()
+
+	tests := []struct {
+		name   string
+		digest []byte
+		ts     *timestamppb.Timestamp
+		full   bool
+	}{
+		{"same configurations", s.digest, configs[0].GetTime(), false},
+		{"server knows a newer configuration", older, configs[1].GetTime(), true},
+		{"client operates on the newest configuration", older, newest, false},
+		{"client knows a newer configuration", configListDigest(benchConfigs(4)), newest, false},
+		{"no digest", nil, newest, true},
+		{"no configuration timestamp", older, nil, true},
+	}
+	for _, test := range tests {
+		mconfs, digest := s.configsFor(test.digest, test.ts)
+		if full := len(mconfs) > 0; full != test.full {
+			t.Errorf("%s: sent %d configurations, want full list %v", test.name, len(mconfs), test.full)
+		}
+		if !test.full && string(digest) != string(test.digest) {
+			t.Errorf("%s: replied with digest %x, want the request's digest", test.name, digest)
+		}
+	}
+}
+
+func TestKnownConfigsEviction(t *testing.T) {
+	known := newKnownConfigs()
+	first := benchConfigs(1)
+	known.learn(first)
+	evicted := known.digest()
+	for n := 2; n <= knownLists+1; n++ {
+		known.learn(benchConfigs(n))
+	}
+	if _, ok := known.lookup(known.digest()); !ok {
+		t.Fatal("the newest list is not remembered")
+	}
+	system, _ := newVotingSystem(3, 0, 0, nil)
+	q := newQSpec(3, system, newKeyring(), known)
+	reply := &proto.ReadResponse{OK: true, ConfigDigest: evicted}
+	if got := replyConfigs(*q, reply); got != nil {
+		t.Errorf("replyConfigs of an evicted list = %v, want none", got)
+	}
+	if known.digest() != nil {
+		t.Error("the client still sends a digest after a list was evicted; want it to ask for the full list")
+	}
+	known.learn(first)
+	if _, ok := known.lookup(known.digest()); !ok {
+		t.Error("a relearned list is not remembered")
+	}
+}
+
+// BenchmarkCombineMConfs compares replies that carry the servers' configurations
+// with replies that carry the digest of the configurations the client already knows.
+// The wire-bytes/op metric is the encoded size of the replies of one quorum call.
+func BenchmarkCombineMConfs(b *testing.B) {
+	const servers = 5
+	for _, n := range []int{1, 4, 16} {
+		configs := benchConfigs(n)
+		known := newKnownConfigs()
+		known.learn(configs)
+		system, _ := newVotingSystem(servers, 0, 0, nil)
+		q := newQSpec(servers, system, newKeyring(), known)
+
+		full := make(map[uint32]*proto.ReadResponse, servers)
+		digest := make(map[uint32]*proto.ReadResponse, servers)
+		for id := uint32(0); id < servers; id++ {
+			full[id] = &proto.ReadResponse{OK: true, Value: "value", Time: timestamppb.Now(), MConfigs: configs}
+			digest[id] = &proto.ReadResponse{OK: true, Value: "value", Time: timestamppb.Now(), ConfigDigest: known.digest()}
+		}
+		for _, bench := range []struct {
+			name    string
+			replies map[uint32]*proto.ReadResponse
+		}{
+			{"full", full},
+			{"digest", digest},
+		} {
+			b.Run(fmt.Sprintf("%s/configs=%d", bench.name, n), func(b *testing.B) {
+				size := 0
+				for _, r := range bench.replies {
+					size += pb.Size(r)
+				}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if got := combineMConfs(*q, bench.replies); len(got) != n {
+						b.Fatalf("combineMConfs returned %d configurations, want %d", len(got), n)
+					}
+				}
+				b.ReportMetric(float64(size), "wire-bytes/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"reconfstorage/proto"
+	"reconfstorage/qf"
+
+	"github.com/relab/gorums"
+)
+
+// Levels reported by a correctable read.
+const (
+	// LevelFirst is reached with the first reply. The value may be stale.
+	LevelFirst = 1
+	// LevelMajority is reached when a majority has replied. The value is confirmed.
+	LevelMajority = 2
+	// LevelAll is reached when all nodes of the configuration have replied.
+	LevelAll = 3
+)
+
+func levelName(level int) string {
+	switch level {
+	case LevelFirst:
+		return "first"
+	case LevelMajority:
+		return "majority"
+	case LevelAll:
+		return "all"
+	}
+	return "none"
+}
+
+// refinement is a reply of a correctable read together with its level
+// and the number of nodes behind it.
+type refinement struct {
+	Resp    *proto.ReadResponse
+	Level   int
+	Replies int
+}
+
+// readCorrectable is the result of a correctable read.
+// Its value is refined as more replicas respond.
+type readCorrectable struct {
+	mu       sync.Mutex
+	last     refinement
+	err      error
+	done     chan struct{}
+	watchers []chan refinement
+}
+
+func newReadCorrectable() *readCorrectable {
+	return &readCorrectable{done: make(chan struct{})}
+}
+
+// update records a new refinement if it has a higher level than the previous one.
+func (rc *readCorrectable) update(r refinement) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.isDone() || r.Level <= rc.last.Level {
+		return
+	}
+	rc.last = r
+	for _, w := range rc.watchers {
+		w <- r
+	}
+}
+
+// finish completes the read with a final refinement or an error.
+func (rc *readCorrectable) finish(r refinement, err error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if err == nil && (r.Level > rc.last.Level || r.Resp != rc.last.Resp) {
+		rc.last = r
+		for _, w := range rc.watchers {
+			w <- r
+		}
+	}
+	rc.err = err
+	for _, w := range rc.watchers {
+		close(w)
+	}
+	rc.watchers = nil
+	close(rc.done)
+}
+
+func (rc *readCorrectable) isDone() bool {
+	select {
+	case <-rc.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Get returns the latest reply, its level and the error, if any, without blocking.
+func (rc *readCorrectable) Get() (*proto.ReadResponse, int, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.last.Resp, rc.last.Level, rc.err
+}
+
+// latest returns the latest refinement.
+func (rc *readCorrectable) latest() refinement {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.last
+}
+
+// Done returns a channel that is closed when the read has completed.
+func (rc *readCorrectable) Done() <-chan struct{} {
+	return rc.done
+}
+
+// Wait blocks until the read has reached level or has completed,
+// and returns the latest reply.
+func (rc *readCorrectable) Wait(level int) (*proto.ReadResponse, int, error) {
+	for r := range rc.Watch() {
+		if r.Level >= level {
+			return r.Resp, r.Level, nil
+		}
+	}
+	return rc.Get()
+}
+
+// Watch returns a channel that receives the current and every later refinement.
+// The channel is closed when the read has completed.
+func (rc *readCorrectable) Watch() <-chan refinement {
+	// buffered for the current value, one refinement per level and the final value
+	ch := make(chan refinement, LevelAll+2)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.last.Level > 0 {
+		ch <- rc.last
+	}
+	if rc.isDone() {
+		close(ch)
+		return ch
+	}
+	rc.watchers = append(rc.watchers, ch)
+	return ch
+}
+
+// readCorrectable reads key from the current configuration, and is refined up to level
+// with the first reply, a read quorum and all replies. Replicas are read with a call each,
+// since Gorums v0.7.0 only reports the final reply of a correctable quorum call.
+// Fenced replies do not count toward a level. The read stops refining once level is reached
+// or all replicas that are up have replied. Once a read quorum has replied, the successor
+// configurations are read as in read, and the confirmed value is reported at the final level.
+func (c *client) readCorrectable(key string, level int) *readCorrectable {
+	return c.readCorrectableFrom(key, level, c.current())
+}
+
+// readCorrectableFrom is like readCorrectable, but reads the configuration start and its successors.
+func (c *client) readCorrectableFrom(key string, level int, start *proto.MetaConfig) *readCorrectable {
+	rc := newReadCorrectable()
+	cfg, qs, err := c.newConfiguration(start)
+	if err != nil {
+		rc.finish(refinement{}, err)
+		return rc
+	}
+	if isCoded(start) {
+		// erasure-coded values are only available once enough fragments are decoded
+		go func() {
+			resp, err := c.readFrom(key, proto.Consistency_MAJORITY, start, nil)
+			rc.finish(refinement{Resp: resp, Level: LevelMajority, Replies: cfg.Size()}, err)
+		}()
+		return rc
+	}
+	req := &proto.ReadRequest{Key: key, ConfigDigest: c.known.digest(), ConfigTime: start.GetTime()}
+	replies, err := c.readEach(cfg, req)
+	if err != nil {
+		rc.finish(refinement{}, err)
+		return rc
+	}
+	go func() {
+		received := make(map[uint32]*proto.ReadResponse, cfg.Size())
+		var errs []NodeError
+		fenced := false
+		for r := range replies {
+			if r.err != nil {
+				errs = append(errs, NodeError{NodeID: r.id, Cause: r.err})
+				continue
+			}
+			received[r.id] = r.resp
+			fenced = fenced || r.resp.GetFenced()
+			resp, reached := qs.readLevel(req, received)
+			if reached == 0 {
+				continue
+			}
+			rc.update(refinement{Resp: resp, Level: reached, Replies: len(qf.Filter(received, notFenced[*proto.ReadResponse]))})
+			if reached >= level {
+				break
+			}
+		}
+
+		last := rc.latest()
+		switch {
+		case last.Level >= LevelMajority:
+			// the value of a read quorum is confirmed, unless a successor configuration has a newer one
+			resp, err := c.readFrom(key, proto.Consistency_MAJORITY, start, last.Resp)
+			rc.finish(refinement{Resp: resp, Level: last.Level, Replies: last.Replies}, err)
+		case last.Level >= level:
+			rc.finish(last, nil)
+		case fenced:
+			// the configuration is superseded; read the newer configurations
+			resp, err := c.readFrom(key, proto.Consistency_MAJORITY, start, nil)
+			rc.finish(refinement{Resp: resp, Level: LevelMajority, Replies: last.Replies}, err)
+		default:
+			rc.finish(refinement{}, &QuorumError{Method: "readCorrectable", Reason: "incomplete call", Replies: len(received), Nodes: errs})
+		}
+	}()
+	return rc
+}
+
+// nodeConfiguration returns the configuration of the single node with the given ID and address,
+// which is created once per node. Each has a voting system of its own, since binding a system
+// to a configuration replaces its nodes. A node the manager does not have yet is dialed.
+func (c *client) nodeConfiguration(id uint32, addr string) (*proto.Configuration, error) {
+	c.dialMu.Lock()
+	defer c.dialMu.Unlock()
+	if node, ok := c.nodeConfigs[id]; ok {
+		return node, nil
+	}
+	system, err := newVotingSystem(1, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	qs := newQSpec(1, system, c.keys, c.known)
+	node, err := c.mgr.NewConfiguration(qs, gorums.WithNodeList([]string{addr}))
+	if err != nil {
+		return nil, err
+	}
+	bindNodes(qs, node, []string{addr})
+	if c.nodeConfigs == nil {
+		c.nodeConfigs = make(map[uint32]*proto.Configuration)
+	}
+	c.nodeConfigs[id] = node
+	return node, nil
+}
+
+// nodeRead is the reply or error of a single replica to readEach.
+type nodeRead struct {
+	id   uint32
+	resp *proto.ReadResponse
+	err  error
+}
+
+// readEach sends req to each node of cfg with a call of its own, and returns a channel
+// that receives the reply or error of every node. It is closed when all nodes have replied,
+// failed or timed out.
+func (c *client) readEach(cfg *proto.Configuration, req *proto.ReadRequest) (<-chan nodeRead, error) {
+	var nodes []*proto.Configuration
+	for _, n := range cfg.Nodes() {
+		node, err := c.nodeConfiguration(n.ID(), n.Address())
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	replies := make(chan nodeRead, len(nodes))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *proto.Configuration) {
+			defer wg.Done()
+			resp, err := node.ReadQC(ctx, req)
+			replies <- nodeRead{id: node.NodeIDs()[0], resp: resp, err: err}
+		}(node)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(replies)
+	}()
+	return replies, nil
+}
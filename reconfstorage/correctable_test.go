@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestQSpec returns the quorum specification of a majority system of n nodes with IDs 1 to n.
+func newTestQSpec(t *testing.T, n int) *qspec {
+	t.Helper()
+	system, err := newVotingSystem(n, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = uint32(i + 1)
+	}
+	q := newQSpec(n, system, newKeyring(), newKnownConfigs())
+	q.ids = ids
+	system.bind(ids)
+	return q
+}
+
+func readReply(value string, sec int64) *proto.ReadResponse {
+	return &proto.ReadResponse{OK: true, Value: value, Time: timestamppb.New(time.Unix(sec, 0))}
+}
+
+func TestReadLevelRefinements(t *testing.T) {
+	q := newTestQSpec(t, 5)
+	in := &proto.ReadRequest{Key: "k"}
+	arrivals := []struct {
+		id    uint32
+		reply *proto.ReadResponse
+		level int
+		value string
+	}{
+		{1, readReply("old", 1), LevelFirst, "old"},
+		{2, &proto.ReadResponse{Fenced: true}, LevelFirst, "old"},
+		{3, readReply("new", 2), LevelFirst, "new"},
+		{4, readReply("old", 1), LevelMajority, "new"},
+		{5, readReply("new", 2), LevelMajority, "new"},
+	}
+	replies := make(map[uint32]*proto.ReadResponse)
+	for _, a := range arrivals {
+		replies[a.id] = a.reply
+		resp, level := q.readLevel(in, replies)
+		if level != a.level || resp.GetValue() != a.value {
+			t.Errorf("after node %d: level %s, value %q; want %s, %q", a.id, levelName(level), resp.GetValue(), levelName(a.level), a.value)
+		}
+	}
+	// the fenced reply is replaced by a current one
+	replies[2] = readReply("old", 1)
+	if _, level := q.readLevel(in, replies); level != LevelAll {
+		t.Errorf("all replied: level %s, want all", levelName(level))
+	}
+}
+
+func TestReadLevelFencedOnly(t *testing.T) {
+	q := newTestQSpec(t, 3)
+	replies := map[uint32]*proto.ReadResponse{1: {Fenced: true}, 2: {Fenced: true}}
+	if resp, level := q.readLevel(&proto.ReadRequest{Key: "k"}, replies); level != 0 || resp != nil {
+		t.Errorf("fenced replies: level %s, want none", levelName(level))
+	}
+}
+
+func TestReadCorrectableWatch(t *testing.T) {
+	rc := newReadCorrectable()
+	first := refinement{Resp: readReply("old", 1), Level: LevelFirst, Replies: 1}
+	rc.update(first)
+	watch := rc.Watch()
+	rc.update(refinement{Resp: readReply("old", 1), Level: LevelFirst, Replies: 2})
+	rc.update(refinement{Resp: readReply("new", 2), Level: LevelMajority, Replies: 3})
+	rc.finish(refinement{Resp: readReply("new", 2), Level: LevelAll, Replies: 5}, nil)
+
+	var levels []int
+	for r := range watch {
+		levels = append(levels, r.Level)
+	}
+	want := []int{LevelFirst, LevelMajority, LevelAll}
+	if len(levels) != len(want) {
+		t.Fatalf("levels %v, want %v", levels, want)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("levels %v, want %v", levels, want)
+		}
+	}
+	if resp, level, err := rc.Get(); err != nil || level != LevelAll || resp.GetValue() != "new" {
+		t.Errorf("Get = %v, %s, %v; want new, all, nil", resp, levelName(level), err)
+	}
+}
+
+func TestReadCorrectableNodeConfigurations(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	if _, err := c.write("k", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		rc := c.readCorrectable("k", LevelAll)
+		<-rc.Done()
+		resp, level, err := rc.Get()
+		if err != nil || level != LevelAll || resp.GetValue() != "v" {
+			t.Fatalf("read %d: %v, %s, %v; want v at level all", i, resp, levelName(level), err)
+		}
+	}
+	// the single-node configurations are made once and reused by later reads
+	if len(c.nodeConfigs) != 3 {
+		t.Errorf("%d single-node configurations, want 3", len(c.nodeConfigs))
+	}
+}
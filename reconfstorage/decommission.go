@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"reconfstorage/proto"
+
+	"github.com/relab/gorums"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// errDecommissioned is returned by a decommissioned server for requests that cannot be redirected.
+// Quorum calls treat it like a server that has crashed.
+var errDecommissioned = errors.New("server decommissioned")
+
+// errUntrustedTombstone is returned for a tombstone that is not signed by a trusted writer.
+var errUntrustedTombstone = errors.New("tombstone not signed by a trusted writer")
+
+// configServers returns the servers of conf, including the old servers of a joint configuration.
+func configServers(conf *proto.MetaConfig) []*proto.Server {
+	return append(append([]*proto.Server(nil), conf.GetServers()...), conf.GetOld().GetServers()...)
+}
+
+// decommission sends a tombstone with the started configuration conf to the servers that are not in conf
+// of the older configurations conf was announced to. These include the configurations that were announced
+// but never started, whose servers may have received state from the transfer.
+// Tombstones are best effort: a removed server that cannot be reached holds no state that the storage still needs.
+func (c *client) decommission(older []*proto.MetaConfig, conf *proto.MetaConfig) {
+	keep := make(map[uint32]bool)
+	for _, s := range configServers(conf) {
+		keep[s.GetID()] = true
+	}
+	removed := make(map[uint32]*proto.Server)
+	for _, old := range older {
+		for _, s := range configServers(old) {
+			if !keep[s.GetID()] {
+				removed[s.GetID()] = s
+			}
+		}
+	}
+	for _, node := range c.mgr.Nodes() {
+		server, ok := removed[node.ID()]
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		tombstone := &proto.Tombstone{Config: conf, Server: server}
+		c.keys.signTombstone(tombstone)
+		_, _ = node.Decommission(ctx, tombstone)
+		cancel()
+	}
+}
+
+// Decommission is an RPC handler
+func (s *storageServer) Decommission(_ gorums.ServerCtx, req *proto.Tombstone) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, s.Bury(req)
+}
+
+// Bury drops the data of the server, which was removed by the started configuration in the tombstone.
+// Later requests are answered with a redirect to the newest configuration, and the server can be shut down.
+// The tombstone is ignored if it is not started, if it includes the server,
+// or if the server knows a newer configuration, which may include the server again.
+// A later configuration that includes the server revives it, see revive.
+// A server with keys, and any server of a Byzantine configuration, only accepts tombstones signed by a trusted writer.
+func (s *storageServer) Bury(req *proto.Tombstone) error {
+	conf := req.GetConfig()
+	if (s.keys != nil || isByzantine(conf)) && !s.keys.verifyTombstone(req) {
+		return errUntrustedTombstone
+	}
+	for _, server := range configServers(conf) {
+		if server.GetID() == req.GetServer().GetID() {
+			return nil
+		}
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if !conf.GetStarted() || s.stopped(conf.GetTime()) {
+		return nil
+	}
+	s.decommissioned = true
+	s.self = req.GetServer()
+	s.storage = make(map[string]state)
+	s.fragments = make(map[string][]version)
+	s.configs = []*proto.MetaConfig{conf}
+	s.digest = configListDigest(s.configs)
+	s.logger.Printf("Decommissioned by '%s', safe to shut down\n", conf.GetAdds())
+	return nil
+}
+
+// revive makes a decommissioned server serve requests again, with empty storage,
+// if the configuration conf includes it. The state transfer to conf then fills the storage.
+// The caller must hold s.mut.
+func (s *storageServer) revive(conf *proto.MetaConfig) {
+	if !s.decommissioned {
+		return
+	}
+	for _, server := range configServers(conf) {
+		if server.GetID() == s.self.GetID() {
+			s.decommissioned = false
+			s.logger.Printf("Added again by '%s'\n", conf.GetAdds())
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// removedBy returns the started configuration of servers 2 and 3, which removes server 1 of the configuration at time 1.
+func removedBy(system string) (*proto.MetaConfig, []*proto.Server) {
+	srv := testServers(3)
+	conf := &proto.MetaConfig{Adds: configString([]string{srv[1].GetAddress(), srv[2].GetAddress()}, system),
+		Time: timestamppb.New(time.Unix(2, 0)), Started: true, Servers: srv[1:]}
+	return conf, srv
+}
+
+func TestBury(t *testing.T) {
+	s := newTestServer()
+	old := configAt(1)
+	if resp, _ := s.Write(&proto.WriteRequest{Key: "k", Value: "v", Time: timestamppb.Now(), ConfigTime: old.GetTime()}); !resp.GetNew() {
+		t.Fatalf("write: %v", resp)
+	}
+	conf, srv := removedBy("")
+
+	// a tombstone that names a server of the configuration is ignored
+	if err := s.Bury(&proto.Tombstone{Config: conf, Server: srv[1]}); err != nil || s.decommissioned {
+		t.Fatalf("tombstone for a server of the configuration: %v, decommissioned %t", err, s.decommissioned)
+	}
+	if resp, _ := s.Read(&proto.ReadRequest{Key: "k", ConfigTime: old.GetTime()}); resp.GetValue() != "v" {
+		t.Errorf("read after an ignored tombstone = %v, want v", resp)
+	}
+
+	if err := s.Bury(&proto.Tombstone{Config: conf, Server: srv[0]}); err != nil || !s.decommissioned {
+		t.Fatalf("tombstone: %v, decommissioned %t", err, s.decommissioned)
+	}
+	if resp, _ := s.Read(&proto.ReadRequest{Key: "k", ConfigTime: old.GetTime()}); !resp.GetFenced() || resp.GetValue() != "" {
+		t.Errorf("read of a buried server = %v, want fenced", resp)
+	}
+	if resp, _ := s.Write(&proto.WriteRequest{Key: "k", Value: "w", Time: timestamppb.Now(), ConfigTime: conf.GetTime()}); !resp.GetFenced() {
+		t.Errorf("write to a buried server = %v, want fenced", resp)
+	}
+	if got := s.configs; len(got) != 1 || configKey(got[0]) != configKey(conf) {
+		t.Errorf("configs = %v, want only the configuration of the tombstone", got)
+	}
+}
+
+func TestBurySigned(t *testing.T) {
+	writer, other := newKeyring(), newKeyring()
+	tests := []struct {
+		name    string
+		keys    *keyring
+		system  string
+		signer  *keyring
+		trusted bool
+	}{
+		{"unsigned without keys", nil, "", nil, true},
+		{"unsigned with keys", trustedKeys(writer.public()), "", nil, false},
+		{"signed by an untrusted writer", trustedKeys(writer.public()), "", other, false},
+		{"signed by a trusted writer", trustedKeys(writer.public()), "", writer, true},
+		{"unsigned Byzantine configuration", nil, "bft:0", nil, false},
+		{"signed Byzantine configuration", trustedKeys(writer.public()), "bft:0", writer, true},
+	}
+	for _, test := range tests {
+		s := newTestServer()
+		s.keys = test.keys
+		conf, srv := removedBy(test.system)
+		tombstone := &proto.Tombstone{Config: conf, Server: srv[0]}
+		if test.signer != nil {
+			test.signer.signTombstone(tombstone)
+		}
+		err := s.Bury(tombstone)
+		if test.trusted != (err == nil) || test.trusted != s.decommissioned {
+			t.Errorf("%s: err = %v, decommissioned %t", test.name, err, s.decommissioned)
+		}
+		if !test.trusted && !errors.Is(err, errUntrustedTombstone) {
+			t.Errorf("%s: err = %v, want %v", test.name, err, errUntrustedTombstone)
+		}
+	}
+	// a tombstone cannot be moved to another server
+	s := newTestServer()
+	s.keys = trustedKeys(writer.public())
+	conf, srv := removedBy("")
+	tombstone := &proto.Tombstone{Config: conf, Server: srv[0]}
+	writer.signTombstone(tombstone)
+	tombstone.Server = &proto.Server{ID: 7, Address: "localhost:7"}
+	if err := s.Bury(tombstone); !errors.Is(err, errUntrustedTombstone) {
+		t.Errorf("tombstone with a changed server: err = %v, want %v", err, errUntrustedTombstone)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Reed-Solomon erasure coding over GF(2^8).
+// A value is split into k data fragments, and n-k parity fragments are added,
+// such that any k of the n fragments recover the value.
+// The code is systematic: the encoding matrix is the k×k identity
+// on top of an (n-k)×k Cauchy matrix, so every k×k submatrix is invertible.
+
+var gfExp, gfLog [512]byte
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < 512; i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// codingRow returns row i of the n×k encoding matrix.
+func codingRow(i, k int) []byte {
+	row := make([]byte, k)
+	if i < k {
+		row[i] = 1
+		return row
+	}
+	for j := range row {
+		// x_i = i and y_j = j are distinct for i ≥ k > j, so x_i + y_j ≠ 0
+		row[j] = gfInv(byte(i) ^ byte(j))
+	}
+	return row
+}
+
+// encode splits value into n fragments, any k of which recover it.
+func encode(value []byte, n, k int) ([]*fragmentData, error) {
+	if k < 1 || n < k || n > 256 {
+		return nil, fmt.Errorf("cannot encode %d-of-%d fragments", k, n)
+	}
+	size := (len(value) + k - 1) / k
+	if size == 0 {
+		size = 1
+	}
+	data := make([][]byte, k)
+	for j := range data {
+		data[j] = make([]byte, size)
+		if j*size < len(value) {
+			copy(data[j], value[j*size:])
+		}
+	}
+	frags := make([]*fragmentData, n)
+	for i := range frags {
+		row := codingRow(i, k)
+		out := make([]byte, size)
+		for j, coef := range row {
+			if coef == 0 {
+				continue
+			}
+			for b, v := range data[j] {
+				out[b] ^= gfMul(coef, v)
+			}
+		}
+		frags[i] = &fragmentData{index: i, data: out}
+	}
+	return frags, nil
+}
+
+// fragmentData is one fragment of an encoded value.
+type fragmentData struct {
+	index int
+	data  []byte
+}
+
+// decode recovers a value of the given size from k of the n fragments.
+func decode(frags []*fragmentData, n, k, size int) ([]byte, error) {
+	if len(frags) < k {
+		return nil, fmt.Errorf("need %d fragments, got %d", k, len(frags))
+	}
+	frags = frags[:k]
+	// invert the rows of the encoding matrix for the available fragments
+	m := make([][]byte, k)
+	inv := make([][]byte, k)
+	for r, f := range frags {
+		if f.index < 0 || f.index >= n || len(f.data) != len(frags[0].data) {
+			return nil, fmt.Errorf("invalid fragment %d", f.index)
+		}
+		m[r] = codingRow(f.index, k)
+		inv[r] = make([]byte, k)
+		inv[r][r] = 1
+	}
+	for col := 0; col < k; col++ {
+		pivot := -1
+		for r := col; r < k; r++ {
+			if m[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, fmt.Errorf("duplicate fragments")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+		scale := gfInv(m[col][col])
+		for j := 0; j < k; j++ {
+			m[col][j] = gfMul(m[col][j], scale)
+			inv[col][j] = gfMul(inv[col][j], scale)
+		}
+		for r := 0; r < k; r++ {
+			if r == col || m[r][col] == 0 {
+				continue
+			}
+			factor := m[r][col]
+			for j := 0; j < k; j++ {
+				m[r][j] ^= gfMul(factor, m[col][j])
+				inv[r][j] ^= gfMul(factor, inv[col][j])
+			}
+		}
+	}
+	fragSize := len(frags[0].data)
+	value := make([]byte, 0, k*fragSize)
+	for j := 0; j < k; j++ {
+		out := make([]byte, fragSize)
+		for r, f := range frags {
+			coef := inv[j][r]
+			if coef == 0 {
+				continue
+			}
+			for b, v := range f.data {
+				out[b] ^= gfMul(coef, v)
+			}
+		}
+		value = append(value, out...)
+	}
+	if size > len(value) {
+		return nil, fmt.Errorf("fragments too short for %d bytes", size)
+	}
+	return value[:size], nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// subsets returns every subset of k of the indices 0 to n-1.
+func subsets(n, k int) [][]int {
+	if k == 0 {
+		return [][]int{nil}
+	}
+	if n < k {
+		return nil
+	}
+	var all [][]int
+	for _, s := range subsets(n-1, k-1) {
+		all = append(all, append(s, n-1))
+	}
+	return append(all, subsets(n-1, k)...)
+}
+
+func TestErasureRoundTrip(t *testing.T) {
+	values := [][]byte{
+		nil,
+		[]byte("x"),
+		[]byte("0123456789abcdefghij"),
+		bytes.Repeat([]byte{0xff, 0x00, 0x7f}, 100),
+	}
+	for _, c := range []struct{ n, k int }{{1, 1}, {3, 1}, {5, 3}, {6, 4}} {
+		for _, value := range values {
+			frags, err := encode(value, c.n, c.k)
+			if err != nil {
+				t.Fatalf("encode %d-of-%d: %v", c.k, c.n, err)
+			}
+			// any k fragments, in any order, recover the value when the others are lost
+			for _, keep := range subsets(c.n, c.k) {
+				kept := make([]*fragmentData, 0, len(keep))
+				for i := len(keep) - 1; i >= 0; i-- {
+					kept = append(kept, frags[keep[i]])
+				}
+				got, err := decode(kept, c.n, c.k, len(value))
+				if err != nil {
+					t.Fatalf("decode %d-of-%d from %v: %v", c.k, c.n, keep, err)
+				}
+				if !bytes.Equal(got, value) {
+					t.Fatalf("decode %d-of-%d from %v = %q, want %q", c.k, c.n, keep, got, value)
+				}
+			}
+		}
+	}
+}
+
+func TestErasureTooFewFragments(t *testing.T) {
+	frags, err := encode([]byte("value"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decode(frags[3:], 5, 3, 5); err == nil {
+		t.Error("decode from 2 of 3 fragments: got no error")
+	}
+	if _, err := decode([]*fragmentData{frags[1], frags[1], frags[4]}, 5, 3, 5); err == nil {
+		t.Error("decode from duplicate fragments: got no error")
+	}
+	if _, err := encode([]byte("value"), 2, 3); err == nil {
+		t.Error("encode 3-of-2: got no error")
+	}
+}
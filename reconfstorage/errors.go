@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/relab/gorums"
+)
+
+// Sentinel errors returned by the client operations.
+// Use errors.Is to check for them and errors.As to get the details.
+var (
+	// ErrQuorumNotReached is reported when a quorum call could not collect enough replies.
+	ErrQuorumNotReached = errors.New("quorum not reached")
+	// ErrStaleTimestamp is reported when a write was rejected because a newer value exists.
+	ErrStaleTimestamp = errors.New("timestamp too old")
+	// ErrConfigSuperseded is reported when a configuration is older than one already known.
+	ErrConfigSuperseded = errors.New("configuration superseded")
+	// ErrInvalidConfig is reported when a configuration string cannot be parsed.
+	ErrInvalidConfig = errors.New("invalid configuration")
+	// ErrSessionViolated is reported when a read returned an older value than the session has seen.
+	ErrSessionViolated = errors.New("session guarantee violated")
+)
+
+// NodeError is the error returned by a single node during a quorum call.
+type NodeError struct {
+	NodeID uint32
+	Cause  error
+}
+
+func (e NodeError) Error() string {
+	return fmt.Sprintf("node %d: %v", e.NodeID, e.Cause)
+}
+
+func (e NodeError) Unwrap() error {
+	return e.Cause
+}
+
+// QuorumError is returned when a quorum call failed.
+// It matches ErrQuorumNotReached and carries the errors of the individual nodes.
+type QuorumError struct {
+	Method  string
+	Reason  string
+	Replies int
+	Nodes   []NodeError
+}
+
+func (e *QuorumError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %v: %s (replies: %d, errors: %d)", e.Method, ErrQuorumNotReached, e.Reason, e.Replies, len(e.Nodes))
+	for _, n := range e.Nodes {
+		b.WriteString("\n\t")
+		b.WriteString(n.Error())
+	}
+	return b.String()
+}
+
+func (e *QuorumError) Is(target error) bool {
+	return target == ErrQuorumNotReached
+}
+
+// NodeErrors returns the errors reported by individual nodes, indexed by node ID.
+func (e *QuorumError) NodeErrors() map[uint32]error {
+	errs := make(map[uint32]error, len(e.Nodes))
+	for _, n := range e.Nodes {
+		errs[n.NodeID] = n.Cause
+	}
+	return errs
+}
+
+// ConfigError is returned when a configuration string is invalid.
+// It matches ErrInvalidConfig.
+type ConfigError struct {
+	Config string
+	Cause  error
+}
+
+func (e *ConfigError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("%v: %q", ErrInvalidConfig, e.Config)
+	}
+	return fmt.Sprintf("%v: %q: %v", ErrInvalidConfig, e.Config, e.Cause)
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Cause
+}
+
+// quorumError converts an error returned by a Gorums quorum call to a QuorumError.
+// Other errors are returned unchanged.
+func quorumError(method string, err error) error {
+	var qcErr gorums.QuorumCallError
+	if !errors.As(err, &qcErr) {
+		return err
+	}
+	nodes := make([]NodeError, 0, len(qcErr.Errors))
+	for _, e := range qcErr.Errors {
+		nodes = append(nodes, NodeError{NodeID: e.NodeID, Cause: e.Cause})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
+	return &QuorumError{Method: method, Reason: qcErr.Reason, Replies: qcErr.ReplyCount, Nodes: nodes}
+}
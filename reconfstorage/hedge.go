@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"reconfstorage/proto"
+
+	"github.com/relab/gorums"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// hedgePercentile is the percentile of recent call durations after which a call is hedged.
+	hedgePercentile = 95
+	// callWindow is the number of recent call durations kept.
+	callWindow = 128
+	// minCallSamples is the number of call durations needed before the percentile is used.
+	minCallSamples = 10
+)
+
+// callTimes keeps the durations of the most recent calls.
+type callTimes struct {
+	mu    sync.Mutex
+	times []time.Duration
+	next  int
+}
+
+func (t *callTimes) record(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.times) < callWindow {
+		t.times = append(t.times, d)
+		return
+	}
+	t.times[t.next] = d
+	t.next = (t.next + 1) % callWindow
+}
+
+// percentile returns the p-th percentile of the recent call durations,
+// or initialTimeout if too few calls have been made.
+func (t *callTimes) percentile(p int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.times) < minCallSamples {
+		return initialTimeout
+	}
+	sorted := append([]time.Duration(nil), t.times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	d := sorted[(len(sorted)-1)*p/100]
+	if d < minTimeout {
+		return minTimeout
+	}
+	return d
+}
+
+// hedgedCall runs a quorum call on all nodes of conf. If the call has not completed
+// when the hedgePercentile of recent call durations has passed, the request is sent again
+// to the nodes that have not replied yet. Both quorum calls share their replies,
+// keeping only the first reply of each node, and the first call to complete returns.
+func hedgedCall[Req any, Resp any](c *client, conf *proto.MetaConfig, req Req, call func(context.Context, *proto.Configuration, Req) (Resp, error)) (resp Resp, err error) {
+	cfg, qs, err := c.newConfiguration(conf)
+	if err != nil {
+		return resp, err
+	}
+	state, end := startCall(req, c.latencies)
+	defer end()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	type result struct {
+		resp Resp
+		err  error
+	}
+	results := make(chan result, 2)
+	send := func(cfg *proto.Configuration) {
+		resp, err := call(ctx, cfg, req)
+		results <- result{resp, err}
+	}
+	go send(cfg)
+	c.stats.add(cfg.Size(), false)
+
+	pending := 1
+	timer := time.NewTimer(c.callTimes.percentile(hedgePercentile))
+	defer timer.Stop()
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				c.callTimes.record(time.Since(state.start))
+				return r.resp, nil
+			}
+			if pending == 0 {
+				return r.resp, r.err
+			}
+			err = r.err
+		case <-timer.C:
+			var missing []uint32
+			for _, n := range cfg.Nodes() {
+				if !state.replied(n.ID()) {
+					missing = append(missing, n.ID())
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			hedge, err := c.createConfiguration(qs, gorums.WithNodeIDs(missing))
+			if err != nil {
+				continue
+			}
+			c.stats.hedge(len(missing))
+			pending++
+			go send(hedge)
+		}
+	}
+}
+
+// isHedged reports whether the client hedges reads and writes.
+func (c *client) isHedged() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hedged
+}
+
+func (c *client) setHedged(hedged bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hedged = hedged
+}
+
+// readHedged reads key from the configuration conf with a hedged quorum call.
+func (c *client) readHedged(key string, level proto.Consistency, conf *proto.MetaConfig) (resp *proto.ReadResponse, err error) {
+	err = c.retry.do(func() error {
+		req := &proto.ReadRequest{Key: key, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()}
+		resp, err = hedgedCall(c, conf, req, func(ctx context.Context, cfg *proto.Configuration, req *proto.ReadRequest) (*proto.ReadResponse, error) {
+			return cfg.ReadQC(ctx, req)
+		})
+		return quorumError("ReadQC", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeHedged writes the value with timestamp ts to the configuration conf with a hedged quorum call.
+// A repeated write with the same timestamp does not change the stored value.
+func (c *client) writeHedged(key, value string, ts *timestamppb.Timestamp, level proto.Consistency, conf *proto.MetaConfig) (resp *proto.WriteResponse, err error) {
+	req := &proto.WriteRequest{Key: key, Value: value, Time: ts, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()}
+	c.keys.signWrite(req)
+	err = c.retry.do(func() error {
+		resp, err = hedgedCall(c, conf, req, func(ctx context.Context, cfg *proto.Configuration, req *proto.WriteRequest) (*proto.WriteResponse, error) {
+			return cfg.WriteQC(ctx, req)
+		})
+		return quorumError("WriteQC", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+)
+
+// TestHedgedCall runs a hedged read in which the first quorum call never completes on its own,
+// so that the read is completed by the hedge sent after the delay.
+func TestHedgedCall(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	if _, err := c.write("k", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	const delay = 20 * time.Millisecond
+	for i := 0; i < minCallSamples; i++ {
+		c.callTimes.record(delay)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	var calls atomic.Int32
+	start := time.Now()
+	req := &proto.ReadRequest{Key: "k", Consistency: proto.Consistency_MAJORITY, ConfigTime: c.current().GetTime()}
+	resp, err := hedgedCall(c, c.current(), req, func(ctx context.Context, cfg *proto.Configuration, req *proto.ReadRequest) (*proto.ReadResponse, error) {
+		if calls.Add(1) == 1 {
+			// the first call is stuck, and would return a stale value once released
+			<-release
+			return &proto.ReadResponse{OK: true, Value: "stale"}, nil
+		}
+		return cfg.ReadQC(ctx, req)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("the read completed after %v, before the hedge delay %v", elapsed, delay)
+	}
+	if resp.GetValue() != "v" {
+		t.Errorf("read = %q, want the value of the hedge", resp.GetValue())
+	}
+	if got := c.stats.snapshot(); got.Hedged != 1 || got.Messages < 6 {
+		t.Errorf("stats = %+v, want one hedge to all three nodes", got)
+	}
+}
+
+// TestHedgedCallFast checks that a call that completes before the delay is not hedged.
+func TestHedgedCallFast(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	for i := 0; i < minCallSamples; i++ {
+		c.callTimes.record(time.Second)
+	}
+	req := &proto.ReadRequest{Key: "k", Consistency: proto.Consistency_MAJORITY, ConfigTime: c.current().GetTime()}
+	if _, err := hedgedCall(c, c.current(), req, func(ctx context.Context, cfg *proto.Configuration, req *proto.ReadRequest) (*proto.ReadResponse, error) {
+		return cfg.ReadQC(ctx, req)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.stats.snapshot(); got.Hedged != 0 {
+		t.Errorf("stats = %+v, want no hedge", got)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultJobTimeout is the time without progress after which a client takes over a reconfiguration.
+const defaultJobTimeout = 5 * time.Second
+
+// jobs are the configurations a client is installing, by their configKey,
+// and the configuration it is watching.
+type jobs struct {
+	mu     sync.Mutex
+	active map[string]bool
+	// watching is the newest unstarted configuration that the watcher of the client waits for, if any
+	watching *proto.MetaConfig
+}
+
+func newJobs() *jobs {
+	return &jobs{active: make(map[string]bool)}
+}
+
+// claim marks conf as active, and reports whether it was not active before.
+func (j *jobs) claim(conf *proto.MetaConfig) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.active[configKey(conf)] {
+		return false
+	}
+	j.active[configKey(conf)] = true
+	return true
+}
+
+func (j *jobs) release(conf *proto.MetaConfig) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.active, configKey(conf))
+}
+
+// follow makes conf the configuration to watch, unless the client installs it or watches a newer one,
+// and reports whether a watcher must be started.
+func (j *jobs) follow(conf *proto.MetaConfig) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.active[configKey(conf)] {
+		return false
+	}
+	if j.watching == nil {
+		j.watching = conf
+		return true
+	}
+	if TimeBefore(j.watching.GetTime(), conf.GetTime()) {
+		j.watching = conf
+	}
+	return false
+}
+
+// watched returns the configuration to watch, and whether the client installs it itself.
+func (j *jobs) watched() (*proto.MetaConfig, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.watching, j.active[configKey(j.watching)]
+}
+
+// unwatch stops watching conf, the configuration the watcher is done with, and reports whether the watcher can stop.
+// If follow has made a newer configuration the one to watch in the meantime, the watcher must go on with it,
+// since follow did not start another watcher.
+func (j *jobs) unwatch(conf *proto.MetaConfig) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.watching != conf {
+		return false
+	}
+	j.watching = nil
+	return true
+}
+
+// advance returns the furthest of the progress a and b of the same reconfiguration.
+func advance(a, b *proto.Progress) *proto.Progress {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	p := &proto.Progress{
+		Config:      a.GetConfig(),
+		Cursor:      a.GetCursor(),
+		Transferred: a.GetTransferred() || b.GetTransferred(),
+		Started:     a.GetStarted() || b.GetStarted(),
+		Updated:     a.GetUpdated(),
+	}
+	if b.GetCursor() > p.GetCursor() {
+		p.Cursor = b.GetCursor()
+	}
+	if TimeBefore(p.GetUpdated(), b.GetUpdated()) {
+		p.Updated = b.GetUpdated()
+	}
+	return p
+}
+
+// job reports the progress of installing a configuration to the servers of the configuration,
+// so that another client can resume the installation.
+type job struct {
+	c        *client
+	cfg      *proto.Configuration
+	progress *proto.Progress
+	reported time.Time
+}
+
+// resumeJob records that conf has been announced, and returns the job with the progress
+// that the servers of conf know, which may have been made by another client.
+func (c *client) resumeJob(conf *proto.MetaConfig, cfg *proto.Configuration) (*job, error) {
+	j := &job{c: c, cfg: cfg, progress: &proto.Progress{Config: conf.GetTime()}}
+	return j, j.report()
+}
+
+// skip reports whether key has already been transferred.
+func (j *job) skip(key string) bool {
+	return j.progress.GetTransferred() || (j.progress.GetCursor() != "" && key <= j.progress.GetCursor())
+}
+
+// transferred records that the keys up to and including key have been transferred.
+// The progress is reported from time to time, well within the job timeout.
+func (j *job) transferred(key string) error {
+	j.progress.Cursor = key
+	if time.Since(j.reported) < j.c.jobTimeout/4 {
+		return nil
+	}
+	return j.report()
+}
+
+// finish records that all keys have been transferred.
+func (j *job) finish() error {
+	j.progress.Transferred = true
+	return j.report()
+}
+
+// start records that the configuration has been started.
+func (j *job) start() error {
+	j.progress.Started = true
+	return j.report()
+}
+
+// report stores the progress on the servers, and adopts the furthest progress they know.
+func (j *job) report() error {
+	j.progress.Updated = timestamppb.Now()
+	p, err := j.c.progressQC(j.cfg, j.progress)
+	if err != nil {
+		return err
+	}
+	j.progress = advance(j.progress, p)
+	j.reported = time.Now()
+	return nil
+}
+
+func (c *client) progressQC(cfg *proto.Configuration, progress *proto.Progress) (resp *proto.Progress, err error) {
+	err = c.retry.do(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err = cfg.ProgressQC(ctx, progress)
+		return quorumError("ProgressQC", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// watch takes over the installation of the unstarted configuration conf,
+// if the client installing it makes no progress for the job timeout.
+// A client runs a single watcher, which follows the newest unstarted configuration it has seen,
+// since installing that configuration also moves the storage past the older ones.
+func (c *client) watch(conf *proto.MetaConfig) {
+	if !c.jobs.follow(conf) {
+		return
+	}
+	go func() {
+		// a configuration followed while the watcher stops is taken over by the same watcher
+		for {
+			if c.jobs.unwatch(c.takeOver()) {
+				return
+			}
+		}
+	}()
+}
+
+// takeOver waits for the watched configuration to be installed, and installs it if its installation
+// makes no progress for the job timeout. It returns the watched configuration it is done with.
+// A joint configuration is installed through to its new configuration.
+func (c *client) takeOver() *proto.MetaConfig {
+	var (
+		target  *proto.MetaConfig
+		cfg     *proto.Configuration
+		noticed time.Time
+	)
+	for {
+		next, installing := c.jobs.watched()
+		if installing || !TimeBefore(c.current().GetTime(), next.GetTime()) {
+			return next
+		}
+		if next != target {
+			var err error
+			if cfg, err = c.parseConfiguration(next); err != nil {
+				return next
+			}
+			target, noticed = next, time.Now()
+		}
+		// only read the progress
+		p, err := c.progressQC(cfg, &proto.Progress{Config: target.GetTime()})
+		if err != nil || p.GetStarted() {
+			return next
+		}
+		last := noticed
+		if p.GetUpdated() != nil && p.GetUpdated().AsTime().After(last) {
+			last = p.GetUpdated().AsTime()
+		}
+		if wait := c.jobTimeout - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+			continue
+		}
+		// the installation fails if a newer configuration was started in the meantime
+		err = c.install(target)
+		if err == nil && isJoint(target) {
+			err = c.leaveJoint()
+		}
+		if err != nil && !errors.Is(err, ErrConfigSuperseded) {
+			log.Printf("take over configuration '%s': %v\n", target.GetAdds(), err)
+		}
+		return next
+	}
+}
+
+// Progress stores the progress of a reconfiguration, and returns the furthest progress known.
+// A request without Updated only reads the progress.
+func (s *storageServer) Progress(req *proto.Progress) (*proto.Progress, error) {
+	s.logger.Printf("Progress up to '%s'\n", req.GetCursor())
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	slot := req.GetConfig().AsTime()
+	// the progress of configurations older than a started configuration is no longer needed
+	if req.GetUpdated() != nil && !s.fenced(req.GetConfig()) {
+		s.jobs[slot] = advance(s.jobs[slot], req)
+	}
+	if p, ok := s.jobs[slot]; ok {
+		return p, nil
+	}
+	return &proto.Progress{Config: req.GetConfig()}, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func configAt(sec int64) *proto.MetaConfig {
+	return &proto.MetaConfig{Adds: "0:3", Time: timestamppb.New(time.Unix(sec, 0))}
+}
+
+func TestJobsFollow(t *testing.T) {
+	j := newJobs()
+	if !j.follow(configAt(2)) {
+		t.Fatal("no watcher started for the first configuration")
+	}
+	if j.follow(configAt(3)) || j.follow(configAt(1)) {
+		t.Error("a second watcher was started")
+	}
+	if w, _ := j.watched(); w.GetTime().GetSeconds() != 3 {
+		t.Errorf("watching %v, want the newest configuration", w.GetTime())
+	}
+	// a configuration the client installs itself is not watched
+	if j.unwatch(configAt(3)) {
+		t.Error("stopped watching with a newer configuration to watch")
+	}
+	if w, _ := j.watched(); !j.unwatch(w) {
+		t.Error("could not stop watching the newest configuration")
+	}
+	installing := configAt(4)
+	j.claim(installing)
+	if j.follow(installing) {
+		t.Error("watching a configuration the client installs")
+	}
+}
+
+// TestJobsFollowConcurrent follows configurations while watchers stop,
+// and checks that the newest configuration is always watched until the end.
+func TestJobsFollowConcurrent(t *testing.T) {
+	const n = 200
+	j := newJobs()
+	var (
+		followers, watchers sync.WaitGroup
+		mu                  sync.Mutex
+		newest              int64
+	)
+	watcher := func() {
+		defer watchers.Done()
+		for {
+			w, _ := j.watched()
+			mu.Lock()
+			if sec := w.GetTime().GetSeconds(); sec > newest {
+				newest = sec
+			}
+			mu.Unlock()
+			if j.unwatch(w) {
+				return
+			}
+		}
+	}
+	for i := int64(1); i <= n; i++ {
+		followers.Add(1)
+		go func(sec int64) {
+			defer followers.Done()
+			if j.follow(configAt(sec)) {
+				watchers.Add(1)
+				go watcher()
+			}
+		}(i)
+	}
+	followers.Wait()
+	watchers.Wait()
+	if newest != n {
+		t.Errorf("newest watched configuration at %d, want %d", newest, n)
+	}
+	if w, _ := j.watched(); w != nil {
+		t.Errorf("still watching %v without a watcher", w.GetTime())
+	}
+}
+
+func TestProgressPruned(t *testing.T) {
+	s := newTestServer()
+	old, next := configAt(1), configAt(2)
+	s.Progress(&proto.Progress{Config: old.GetTime(), Cursor: "k", Updated: timestamppb.Now()})
+	started := &proto.MetaConfig{Adds: next.GetAdds(), Time: next.GetTime(), Started: true}
+	if _, err := s.WriteConfig(started); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.jobs[old.GetTime().AsTime()]; ok {
+		t.Error("the progress of the older configuration was kept")
+	}
+	// late reports for the older configuration are not stored again
+	s.Progress(&proto.Progress{Config: old.GetTime(), Cursor: "m", Updated: timestamppb.Now()})
+	if _, ok := s.jobs[old.GetTime().AsTime()]; ok {
+		t.Error("a late report for the older configuration was stored")
+	}
+}
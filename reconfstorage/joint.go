@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"reconfstorage/proto"
+
+	pb "google.golang.org/protobuf/proto"
+)
+
+// jointSystem is the quorum system of a joint configuration C_old,new, as in Raft's joint consensus.
+// A set of nodes is a quorum if it contains a quorum of both the old and the new quorum system,
+// so quorums of the joint configuration intersect the quorums of either.
+type jointSystem struct {
+	old, next quorumSystem
+	// oldPos and nextPos are the positions of the servers of the old and new configuration
+	// in the joint configuration
+	oldPos, nextPos []int
+}
+
+func newJointSystem(old quorumSystem, oldPos []int, next quorumSystem, nextPos []int) (*jointSystem, error) {
+	for _, s := range []quorumSystem{old, next} {
+		if faultTolerance(s) > 0 || dataFragments(s) > 0 {
+			return nil, fmt.Errorf("joint configurations do not support %s quorums", s)
+		}
+	}
+	return &jointSystem{old: old, next: next, oldPos: oldPos, nextPos: nextPos}, nil
+}
+
+func (q *jointSystem) bind(ids []uint32) {
+	q.old.bind(pick(ids, q.oldPos))
+	q.next.bind(pick(ids, q.nextPos))
+}
+
+// pick returns the IDs at the given positions.
+func pick(ids []uint32, pos []int) []uint32 {
+	picked := make([]uint32, len(pos))
+	for i, p := range pos {
+		picked[i] = ids[p]
+	}
+	return picked
+}
+
+func (q *jointSystem) isReadQuorum(ids map[uint32]bool) bool {
+	return q.old.isReadQuorum(ids) && q.next.isReadQuorum(ids)
+}
+
+func (q *jointSystem) isWriteQuorum(ids map[uint32]bool) bool {
+	return q.old.isWriteQuorum(ids) && q.next.isWriteQuorum(ids)
+}
+
+func (q *jointSystem) isMetaQuorum(ids map[uint32]bool) bool {
+	return q.old.isMetaQuorum(ids) && q.next.isMetaQuorum(ids)
+}
+
+func (q *jointSystem) minReadQuorum() []int {
+	return q.union(q.old.minReadQuorum(), q.next.minReadQuorum())
+}
+
+func (q *jointSystem) minWriteQuorum() []int {
+	return q.union(q.old.minWriteQuorum(), q.next.minWriteQuorum())
+}
+
+// union returns the positions in the joint configuration of the positions old and next of the old and new quorum system.
+func (q *jointSystem) union(old, next []int) []int {
+	set := make(map[int]bool)
+	for _, p := range old {
+		set[q.oldPos[p]] = true
+	}
+	for _, p := range next {
+		set[q.nextPos[p]] = true
+	}
+	pos := make([]int, 0, len(set))
+	for p := range set {
+		pos = append(pos, p)
+	}
+	sort.Ints(pos)
+	return pos
+}
+
+func (q *jointSystem) String() string {
+	return fmt.Sprintf("joint (old: %s, new: %s)", q.old, q.next)
+}
+
+// parseJointSystem returns the quorum system of the joint configuration conf,
+// and the servers of the old configuration followed by the new servers.
+func (c *client) parseJointSystem(conf *proto.MetaConfig) (quorumSystem, []string, error) {
+	old, oldNodes, err := c.parseQuorumSystem(conf.GetOld())
+	if err != nil {
+		return nil, nil, err
+	}
+	nextConf := pb.Clone(conf).(*proto.MetaConfig)
+	nextConf.Old = nil
+	next, nextNodes, err := c.parseQuorumSystem(nextConf)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes := append([]string(nil), oldNodes...)
+	pos := make(map[string]int, len(oldNodes)+len(nextNodes))
+	oldPos := make([]int, len(oldNodes))
+	for i, addr := range oldNodes {
+		pos[addr] = i
+		oldPos[i] = i
+	}
+	nextPos := make([]int, len(nextNodes))
+	for i, addr := range nextNodes {
+		p, ok := pos[addr]
+		if !ok {
+			p = len(nodes)
+			pos[addr] = p
+			nodes = append(nodes, addr)
+		}
+		nextPos[i] = p
+	}
+	system, err := newJointSystem(old, oldPos, next, nextPos)
+	if err != nil {
+		return nil, nil, &ConfigError{Config: conf.GetAdds(), Cause: err}
+	}
+	return system, nodes, nil
+}
+
+// isJoint reports whether conf is a joint configuration.
+func isJoint(conf *proto.MetaConfig) bool {
+	return conf.GetOld() != nil
+}
+
+// reconfJoint moves the storage to the configuration goal in the two steps of joint consensus:
+// first to the joint configuration C_old,new, and then to C_new.
+func (c *client) reconfJoint(goal *proto.MetaConfig) error {
+	if _, err := c.enterJoint(goal); err != nil {
+		return err
+	}
+	return c.leaveJoint()
+}
+
+// enterJoint installs the joint configuration of the current configuration and goal, and returns it.
+// Once it is started, reads and writes need quorums of both the old and the new servers.
+// If the current configuration is still joint, its transition is finished first.
+func (c *client) enterJoint(goal *proto.MetaConfig) (*proto.MetaConfig, error) {
+	goal, err := c.resolve(goal)
+	if err != nil {
+		return nil, err
+	}
+	if isJoint(c.current()) {
+		if err := c.leaveJoint(); err != nil {
+			return nil, err
+		}
+	}
+	old := pb.Clone(c.current()).(*proto.MetaConfig)
+	old.Started = false
+	old.Signature, old.Writer = nil, nil
+
+	joint := pb.Clone(goal).(*proto.MetaConfig)
+	joint.Started = false
+	joint.Old = old
+	joint.Time = timestampAfter(old.GetTime())
+	if err := c.install(joint); err != nil {
+		return nil, fmt.Errorf("joint configuration: %w", err)
+	}
+	return c.current(), nil
+}
+
+// leaveJoint moves the storage from the current joint configuration to its new configuration.
+func (c *client) leaveJoint() error {
+	next := pb.Clone(c.current()).(*proto.MetaConfig)
+	if !isJoint(next) {
+		return nil
+	}
+	next.Started = false
+	next.Old = nil
+	next.Signature, next.Writer = nil, nil
+	next.Time = timestampAfter(next.GetTime())
+	if err := c.install(next); err != nil {
+		return fmt.Errorf("new configuration: %w", err)
+	}
+	return nil
+}
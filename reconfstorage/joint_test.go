@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"reconfstorage/proto"
+)
+
+// resolvedConfig returns the configuration of the servers addrs with the quorum system spec.
+func resolvedConfig(t *testing.T, addrs []string, system string) *proto.MetaConfig {
+	t.Helper()
+	conf := &proto.MetaConfig{Adds: configString(addrs, system)}
+	for _, addr := range addrs {
+		server, err := newServer(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf.Servers = append(conf.Servers, server)
+	}
+	return conf
+}
+
+func TestParseJointSystem(t *testing.T) {
+	// old: 1 2 3, new: 2 3 4 5
+	addrs := []string{"127.0.0.1:9001", "127.0.0.1:9002", "127.0.0.1:9003", "127.0.0.1:9004", "127.0.0.1:9005"}
+	joint := resolvedConfig(t, addrs[1:], "")
+	joint.Old = resolvedConfig(t, addrs[:3], "")
+
+	c := &client{}
+	system, nodes, err := c.parseJointSystem(joint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the servers of the old configuration come first, and shared servers appear once
+	if len(nodes) != len(addrs) {
+		t.Fatalf("nodes = %v, want %v", nodes, addrs)
+	}
+	for i := range addrs {
+		if nodes[i] != addrs[i] {
+			t.Fatalf("nodes = %v, want %v", nodes, addrs)
+		}
+	}
+	checkQuorums(t, bindTest(system, len(nodes)), []quorumCase{
+		{"old majority only", nodeSet(1, 2), false, false, false},
+		{"new majority only", nodeSet(3, 4, 5), false, false, false},
+		{"majority of both", nodeSet(2, 3, 4), true, true, true},
+		{"majority of both with old server", nodeSet(1, 3, 4, 5), true, true, true},
+	})
+	if got := system.minReadQuorum(); len(got) < 3 {
+		t.Errorf("minReadQuorum = %v, want at least 3 positions", got)
+	}
+}
+
+func TestJointSystemQuorumSizes(t *testing.T) {
+	// old: 1 2 3 with read one, write all; new: 3 4 5 with majorities
+	old, err := newVotingSystem(3, 1, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := newVotingSystem(3, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system, err := newJointSystem(old, []int{0, 1, 2}, next, []int{2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(system, 5), []quorumCase{
+		{"one old and two new", nodeSet(1, 4, 5), true, false, false},
+		{"shared server and one new", nodeSet(3, 4), true, false, false},
+		{"all old and two new", nodeSet(1, 2, 3, 4), true, true, true},
+		{"two old and all new", nodeSet(1, 2, 3, 4, 5), true, true, true},
+		{"two old and two new", nodeSet(1, 2, 4, 5), true, false, false},
+	})
+}
+
+func TestJointSystemRejectsByzantine(t *testing.T) {
+	bft, err := newByzantineSystem(4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	majority, err := newVotingSystem(3, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newJointSystem(majority, []int{0, 1, 2}, bft, []int{0, 1, 2, 3}); err == nil {
+		t.Error("joint configuration with a Byzantine system: got no error")
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// changeKey identifies a change. A server is added and removed at most once.
+func changeKey(ch *proto.Change) string {
+	return fmt.Sprintf("%t/%d", ch.GetRemove(), ch.GetServer().GetID())
+}
+
+// joinChanges returns the union of the sets of changes, ordered by changeKey.
+// The union is the join of the lattice of configurations.
+func joinChanges(sets ...[]*proto.Change) []*proto.Change {
+	joined := make(map[string]*proto.Change)
+	for _, set := range sets {
+		for _, ch := range set {
+			joined[changeKey(ch)] = ch
+		}
+	}
+	changes := make([]*proto.Change, 0, len(joined))
+	for _, ch := range joined {
+		changes = append(changes, ch)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changeKey(changes[i]) < changeKey(changes[j]) })
+	return changes
+}
+
+// includes reports whether the set of changes a includes all changes of b.
+func includes(a, b []*proto.Change) bool {
+	keys := make(map[string]bool, len(a))
+	for _, ch := range a {
+		keys[changeKey(ch)] = true
+	}
+	for _, ch := range b {
+		if !keys[changeKey(ch)] {
+			return false
+		}
+	}
+	return true
+}
+
+// changesOf returns the changes of conf. A configuration that was not made by lattice agreement
+// is made up of the additions of its servers.
+func changesOf(conf *proto.MetaConfig) []*proto.Change {
+	if len(conf.GetChanges()) > 0 {
+		return conf.GetChanges()
+	}
+	changes := make([]*proto.Change, 0, len(conf.GetServers()))
+	for _, s := range conf.GetServers() {
+		changes = append(changes, &proto.Change{Server: s})
+	}
+	return joinChanges(changes)
+}
+
+// members returns the servers that are added and not removed by changes, ordered by ID.
+func members(changes []*proto.Change) []*proto.Server {
+	removed := make(map[uint32]bool)
+	for _, ch := range changes {
+		if ch.GetRemove() {
+			removed[ch.GetServer().GetID()] = true
+		}
+	}
+	var servers []*proto.Server
+	for _, ch := range changes {
+		if !ch.GetRemove() && !removed[ch.GetServer().GetID()] {
+			servers = append(servers, ch.GetServer())
+		}
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].GetID() < servers[j].GetID() })
+	return servers
+}
+
+// goalChanges returns the changes that turn the servers of conf into the servers of goal.
+func goalChanges(conf, goal *proto.MetaConfig) []*proto.Change {
+	current := make(map[uint32]bool)
+	for _, s := range members(changesOf(conf)) {
+		current[s.GetID()] = true
+	}
+	wanted := make(map[uint32]bool)
+	var changes []*proto.Change
+	for _, s := range goal.GetServers() {
+		wanted[s.GetID()] = true
+		if !current[s.GetID()] {
+			changes = append(changes, &proto.Change{Server: s})
+		}
+	}
+	for _, s := range members(changesOf(conf)) {
+		if !wanted[s.GetID()] {
+			changes = append(changes, &proto.Change{Remove: true, Server: s})
+		}
+	}
+	return changes
+}
+
+// latticeStep is the time between a configuration and a configuration of the lattice after it, per change.
+// Successors that are not made by lattice agreement are timestamped by timestampAfter,
+// which never picks a whole number of steps after the configuration.
+const latticeStep = time.Microsecond
+
+// latticeConfig returns the configuration made up of changes, which include the changes of conf,
+// with the quorum system, quorum sizes and weights of quorums.
+// Its timestamp grows with the number of changes, so that a larger configuration of the lattice is newer,
+// and all clients derive the same configuration from the same changes and quorums.
+// Servers that quorums has no weight for get one vote.
+func latticeConfig(conf *proto.MetaConfig, changes []*proto.Change, quorums *proto.MetaConfig) *proto.MetaConfig {
+	servers := members(changes)
+	addrs := make([]string, len(servers))
+	for i, s := range servers {
+		addrs[i] = s.GetAddress()
+	}
+	_, system := splitConfig(quorums.GetAdds())
+	ts := conf.GetTime().AsTime().Add(latticeStep * time.Duration(len(changes)-len(changesOf(conf))))
+	next := &proto.MetaConfig{Adds: configString(addrs, system), Time: timestamppb.New(ts), Servers: servers, Changes: changes,
+		ReadQuorum: quorums.GetReadQuorum(), WriteQuorum: quorums.GetWriteQuorum()}
+	if weights := weightsByID(quorums); len(weights) > 0 {
+		next.Weights = make([]uint32, len(servers))
+		for i, s := range servers {
+			w, ok := weights[s.GetID()]
+			if !ok {
+				w = 1
+			}
+			next.Weights[i] = w
+		}
+	}
+	return next
+}
+
+// quorumsOf returns the quorum system, quorum sizes and weights of conf,
+// with the weights kept together with the servers they belong to.
+func quorumsOf(conf *proto.MetaConfig) *proto.MetaConfig {
+	_, system := splitConfig(conf.GetAdds())
+	quorums := &proto.MetaConfig{Adds: configString(nil, system), ReadQuorum: conf.GetReadQuorum(), WriteQuorum: conf.GetWriteQuorum()}
+	if len(conf.GetWeights()) > 0 {
+		quorums.Servers = conf.GetServers()
+		quorums.Weights = conf.GetWeights()
+	}
+	return quorums
+}
+
+// weightsByID returns the weights of conf by server ID.
+func weightsByID(conf *proto.MetaConfig) map[uint32]uint32 {
+	weights := make(map[uint32]uint32, len(conf.GetWeights()))
+	for i, w := range conf.GetWeights() {
+		if i < len(conf.GetServers()) {
+			weights[conf.GetServers()[i].GetID()] = w
+		}
+	}
+	return weights
+}
+
+// quorumKey identifies the quorum system, quorum sizes and weights of conf.
+func quorumKey(conf *proto.MetaConfig) string {
+	_, system := splitConfig(conf.GetAdds())
+	weights := weightsByID(conf)
+	ids := make([]uint32, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	key := fmt.Sprintf("%s/%d/%d", system, conf.GetReadQuorum(), conf.GetWriteQuorum())
+	for _, id := range ids {
+		key += fmt.Sprintf("/%d:%d", id, weights[id])
+	}
+	return key
+}
+
+// configKey identifies a configuration when the configurations known by servers are merged.
+// Configurations of the lattice are identified by their changes, and others by their timestamp.
+func configKey(conf *proto.MetaConfig) string {
+	if len(conf.GetChanges()) == 0 {
+		return conf.GetTime().AsTime().String()
+	}
+	changes := joinChanges(conf.GetChanges())
+	keys := make([]string, len(changes))
+	for i, ch := range changes {
+		keys[i] = changeKey(ch)
+	}
+	return strings.Join(keys, ",")
+}
+
+// joinLattice returns confs and, if the configurations of the lattice in confs are not ordered by inclusion,
+// their join. Concurrent proposals decided from different configurations are not ordered,
+// and installing their join, which any client that learns of them takes over, makes the clients converge on it.
+// The join is derived from the newest of the largest configurations, so that it is newer than all of them,
+// and has its quorums.
+func joinLattice(confs []*proto.MetaConfig) []*proto.MetaConfig {
+	var largest []*proto.MetaConfig
+	for _, c := range confs {
+		if len(c.GetChanges()) == 0 {
+			continue
+		}
+		included := false
+		for _, d := range confs {
+			if len(d.GetChanges()) > len(c.GetChanges()) && includes(d.GetChanges(), c.GetChanges()) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			largest = append(largest, c)
+		}
+	}
+	if len(largest) < 2 {
+		return confs
+	}
+	sort.Slice(largest, func(i, j int) bool {
+		if !largest[i].GetTime().AsTime().Equal(largest[j].GetTime().AsTime()) {
+			return TimeBefore(largest[j].GetTime(), largest[i].GetTime())
+		}
+		return configKey(largest[i]) < configKey(largest[j])
+	})
+	changes := make([][]*proto.Change, len(largest))
+	for i, c := range largest {
+		changes[i] = c.GetChanges()
+	}
+	return append(confs, latticeConfig(largest[0], joinChanges(changes...), largest[0]))
+}
+
+// reconfLattice moves the storage to a configuration that includes the changes from the current configuration to goal.
+// The servers of the current configuration agree on a set of changes with generalized lattice agreement,
+// which may include the changes proposed concurrently by other clients, and the resulting configuration is installed.
+// Concurrent clients thus converge on the union of their changes, without consensus.
+// Since the servers agree separately on the successor of each configuration,
+// the client first moves to the newest started configuration.
+func (c *client) reconfLattice(goal *proto.MetaConfig) error {
+	if err := c.refresh(); err != nil {
+		return err
+	}
+	cur := c.current()
+	quorums := quorumsOf(goal)
+	decided, err := c.agree(cur, joinChanges(changesOf(cur), goalChanges(cur, goal)), quorums)
+	if err != nil {
+		return fmt.Errorf("lattice agreement: %w", err)
+	}
+	next := latticeConfig(cur, decided, quorums)
+	if !TimeBefore(cur.GetTime(), next.GetTime()) {
+		// the current configuration already includes the changes
+		return nil
+	}
+	err = c.install(next)
+	if errors.Is(err, ErrConfigSuperseded) {
+		// a larger configuration of the lattice, which includes the decided changes, was installed concurrently
+		return nil
+	}
+	return err
+}
+
+// agree runs generalized lattice agreement among the servers of conf, starting with proposal.
+// A proposal accepted by a meta quorum is decided. Otherwise the changes accepted by the servers
+// are joined into the proposal, and it is proposed again. The decided sets of changes are ordered by inclusion.
+// All proposals for the successor of conf must have the same quorums; agreement fails if the servers have accepted others.
+func (c *client) agree(conf *proto.MetaConfig, proposal []*proto.Change, quorums *proto.MetaConfig) ([]*proto.Change, error) {
+	cfg, err := c.parseConfiguration(conf)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var resp *proto.LatticeResponse
+		err := c.retry.do(func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			var err error
+			resp, err = cfg.ProposeQC(ctx, &proto.LatticeRequest{Proposal: proposal, Config: conf, Quorums: quorums})
+			return quorumError("ProposeQC", err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.GetOK() {
+			return proposal, nil
+		}
+		if resp.GetQuorums() != nil {
+			return nil, &ConfigError{Config: conf.GetAdds(), Cause: fmt.Errorf("a concurrent reconfiguration uses the quorums %q", quorumKey(resp.GetQuorums()))}
+		}
+		proposal = joinChanges(proposal, resp.GetAccepted())
+	}
+}
+
+// Propose accepts the proposed changes if they include the changes the server has accepted
+// for the successor of the requested configuration, which start with the changes of that configuration.
+// Otherwise the proposal is joined into the accepted changes, which are returned.
+// Proposals with other quorums than the first proposal for the successor are rejected with those quorums.
+func (s *storageServer) Propose(req *proto.LatticeRequest) (*proto.LatticeResponse, error) {
+	s.logger.Printf("Propose %d changes\n", len(req.GetProposal()))
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.decommissioned {
+		return nil, errDecommissioned
+	}
+	key := configKey(req.GetConfig())
+	accepted, ok := s.lattices[key]
+	if !ok {
+		accepted = changesOf(req.GetConfig())
+	}
+	if quorums, ok := s.latticeQuorums[key]; !ok {
+		s.latticeQuorums[key] = req.GetQuorums()
+	} else if quorumKey(quorums) != quorumKey(req.GetQuorums()) {
+		return &proto.LatticeResponse{OK: false, Accepted: accepted, Quorums: quorums}, nil
+	}
+	if includes(req.GetProposal(), accepted) {
+		s.lattices[key] = joinChanges(req.GetProposal())
+		return &proto.LatticeResponse{OK: true}, nil
+	}
+	s.lattices[key] = joinChanges(accepted, req.GetProposal())
+	return &proto.LatticeResponse{OK: false, Accepted: s.lattices[key]}, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testServers returns the servers with IDs 1 to n.
+func testServers(n int) []*proto.Server {
+	servers := make([]*proto.Server, n)
+	for i := range servers {
+		servers[i] = &proto.Server{ID: uint32(i + 1), Address: "localhost:" + string(rune('0'+i+1))}
+	}
+	return servers
+}
+
+// baseConfig returns a configuration made without lattice agreement of the servers.
+func baseConfig(servers ...*proto.Server) *proto.MetaConfig {
+	return &proto.MetaConfig{Time: timestamppb.New(time.Unix(1, 0)), Servers: servers}
+}
+
+func add(s *proto.Server) *proto.Change    { return &proto.Change{Server: s} }
+func remove(s *proto.Server) *proto.Change { return &proto.Change{Remove: true, Server: s} }
+
+func TestLatticeConfig(t *testing.T) {
+	srv := testServers(4)
+	base := baseConfig(srv[0], srv[1], srv[2])
+	next := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[3]), remove(srv[0])}), base)
+	if got := members(next.GetChanges()); len(got) != 3 || got[0].GetID() != 2 || got[2].GetID() != 4 {
+		t.Errorf("members = %v, want servers 2 to 4", got)
+	}
+	if !TimeBefore(base.GetTime(), next.GetTime()) {
+		t.Error("the configuration with more changes is not newer")
+	}
+	// the same changes, in any order, give the same configuration
+	again := latticeConfig(base, joinChanges([]*proto.Change{remove(srv[0]), add(srv[3])}, changesOf(base)), base)
+	if configKey(again) != configKey(next) || !again.GetTime().AsTime().Equal(next.GetTime().AsTime()) {
+		t.Errorf("%v and %v differ", again, next)
+	}
+	if got := goalChanges(base, &proto.MetaConfig{Servers: []*proto.Server{srv[1], srv[2], srv[3]}}); len(got) != 2 {
+		t.Errorf("goalChanges = %v, want adding 4 and removing 1", got)
+	}
+}
+
+func TestProposePerConfiguration(t *testing.T) {
+	s := newTestServer()
+	srv := testServers(5)
+	base := baseConfig(srv[0], srv[1], srv[2])
+	c1 := latticeConfig(base, changesOf(base), base)
+	c2 := latticeConfig(c1, joinChanges(c1.GetChanges(), []*proto.Change{add(srv[3])}), c1)
+
+	first := joinChanges(c1.GetChanges(), []*proto.Change{add(srv[3])})
+	if resp, _ := s.Propose(&proto.LatticeRequest{Config: c1, Proposal: first}); !resp.GetOK() {
+		t.Fatalf("first proposal for c1 rejected: %v", resp)
+	}
+	// a concurrent proposal for c1 that misses the accepted change is rejected with it
+	second := joinChanges(c1.GetChanges(), []*proto.Change{add(srv[4])})
+	resp, _ := s.Propose(&proto.LatticeRequest{Config: c1, Proposal: second})
+	if resp.GetOK() || !includes(resp.GetAccepted(), first) || !includes(resp.GetAccepted(), second) {
+		t.Errorf("second proposal for c1: %v, want rejected with the join", resp)
+	}
+	// agreement on the successor of c2 starts from the changes of c2
+	if resp, _ := s.Propose(&proto.LatticeRequest{Config: c2, Proposal: c1.GetChanges()}); resp.GetOK() {
+		t.Error("accepted a proposal for c2 without the changes of c2")
+	}
+	if resp, _ := s.Propose(&proto.LatticeRequest{Config: c2, Proposal: joinChanges(c2.GetChanges(), []*proto.Change{remove(srv[0])})}); !resp.GetOK() {
+		t.Errorf("proposal for c2 rejected: %v", resp)
+	}
+}
+
+func TestJoinLattice(t *testing.T) {
+	srv := testServers(5)
+	base := baseConfig(srv[0], srv[1], srv[2])
+	withFour := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[3])}), base)
+	withFive := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[4])}), base)
+	both := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[3]), add(srv[4])}), base)
+
+	// configurations ordered by inclusion are returned as they are
+	if got := joinLattice([]*proto.MetaConfig{base, withFour, both}); len(got) != 3 {
+		t.Errorf("ordered configurations: %d configurations, want 3", len(got))
+	}
+	// the timestamps of concurrent proposals are equal, so they are told apart by their changes
+	if configKey(withFour) == configKey(withFive) {
+		t.Fatal("configurations with different changes have the same key")
+	}
+	got := joinLattice([]*proto.MetaConfig{base, withFour, withFive})
+	if len(got) != 4 {
+		t.Fatalf("concurrent configurations: %d configurations, want 4", len(got))
+	}
+	join := got[3]
+	if configKey(join) != configKey(both) || !join.GetTime().AsTime().Equal(both.GetTime().AsTime()) || join.GetStarted() {
+		t.Errorf("join = %v, want %v", join, both)
+	}
+}
+
+func TestAddConfigsLatticeKeys(t *testing.T) {
+	srv := testServers(5)
+	base := baseConfig(srv[0], srv[1], srv[2])
+	withFour := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[3])}), base)
+	withFive := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[4])}), base)
+	c := &client{known: newKnownConfigs(), jobs: newJobs()}
+	c.adopt(base)
+	// the client is not connected, so it must not watch the configurations
+	c.jobs.claim(withFour)
+	c.jobs.claim(withFive)
+
+	confmap := c.addConfigs(map[string]*proto.MetaConfig{configKey(base): base}, base, []*proto.MetaConfig{withFour, withFive})
+	if len(confmap) != 3 {
+		t.Errorf("confmap has %d configurations, want 3", len(confmap))
+	}
+}
+
+func TestLatticeConfigQuorums(t *testing.T) {
+	srv := testServers(4)
+	base := baseConfig(srv[0], srv[1], srv[2])
+	// the goal gives server 2 three votes, and does not know server 4, which is added concurrently
+	goal := &proto.MetaConfig{Adds: "@majority", Servers: []*proto.Server{srv[1], srv[0], srv[2]}, Weights: []uint32{3, 1, 1}, ReadQuorum: 3, WriteQuorum: 4}
+	next := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{add(srv[3])}), quorumsOf(goal))
+	if _, system := splitConfig(next.GetAdds()); system != "majority" || next.GetReadQuorum() != 3 || next.GetWriteQuorum() != 4 {
+		t.Errorf("next = %v, want the quorum system and sizes of the goal", next)
+	}
+	want := map[uint32]uint32{1: 1, 2: 3, 3: 1, 4: 1}
+	got := weightsByID(next)
+	for id, w := range want {
+		if got[id] != w {
+			t.Errorf("weights = %v, want %v", got, want)
+			break
+		}
+	}
+	// the join has the quorums of the newest configuration
+	other := latticeConfig(base, joinChanges(changesOf(base), []*proto.Change{remove(srv[0])}), base)
+	joined := joinLattice([]*proto.MetaConfig{next, other})
+	if len(joined) != 3 {
+		t.Fatalf("joinLattice = %v, want the join", joined)
+	}
+	if join := joined[2]; join.GetReadQuorum() != 3 || join.GetWriteQuorum() != 4 || weightsByID(join)[2] != 3 {
+		t.Errorf("join = %v, want the quorums of %v", join, next)
+	}
+}
+
+func TestProposeQuorums(t *testing.T) {
+	s := newTestServer()
+	srv := testServers(4)
+	base := baseConfig(srv[0], srv[1], srv[2])
+	majority := quorumsOf(base)
+	grid := quorumsOf(&proto.MetaConfig{Adds: "@grid:2x2"})
+
+	proposal := joinChanges(changesOf(base), []*proto.Change{add(srv[3])})
+	if resp, _ := s.Propose(&proto.LatticeRequest{Config: base, Proposal: proposal, Quorums: grid}); !resp.GetOK() {
+		t.Fatalf("first proposal rejected: %v", resp)
+	}
+	resp, _ := s.Propose(&proto.LatticeRequest{Config: base, Proposal: proposal, Quorums: majority})
+	if resp.GetOK() || quorumKey(resp.GetQuorums()) != quorumKey(grid) {
+		t.Errorf("proposal with other quorums: %v, want rejected with the grid", resp)
+	}
+	if resp, _ := s.Propose(&proto.LatticeRequest{Config: base, Proposal: proposal, Quorums: grid}); !resp.GetOK() {
+		t.Errorf("proposal with the accepted quorums rejected: %v", resp)
+	}
+}
+
+func TestTimestampAfterLattice(t *testing.T) {
+	// the clock is behind the configuration
+	ts := timestamppb.New(time.Now().Add(time.Hour))
+	if got := timestampAfter(ts); !TimeBefore(ts, got) || got.AsTime().Sub(ts.AsTime())%latticeStep == 0 {
+		t.Errorf("timestampAfter = %v, want after %v and between the timestamps of the lattice", got, ts)
+	}
+	for i := 0; i < 100; i++ {
+		ts := timestamppb.New(time.Now().Truncate(latticeStep))
+		if got := timestampAfter(ts); got.AsTime().Sub(ts.AsTime())%latticeStep == 0 {
+			t.Fatalf("timestampAfter(%v) = %v, a timestamp of the lattice", ts, got)
+		}
+	}
+}
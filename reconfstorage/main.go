@@ -11,20 +11,32 @@ import (
 func main() {
 	server := flag.String("server", "", "Start as a server on given address.")
 	remotes := flag.String("connect", "", "Comma-separated list of servers to connect to.")
+	keyFile := flag.String("key", "", "File with the client's signing key. It is created if it does not exist.")
+	trust := flag.String("trust", "", "Comma-separated list of public keys of other trusted writers.")
 	flag.Parse()
 
 	if *server != "" {
-		runServer(*server)
+		trusted, err := parseTrusted(*trust)
+		if err != nil {
+			log.Fatal(err)
+		}
+		runServer(*server, trusted...)
 		return
 	}
 
+	keys, err := loadKeyring(*keyFile, *trust)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Public key %x\n", keys.public())
+
 	addrs := strings.Split(*remotes, ",")
 	// start local servers if no remote servers were specified
 	if len(addrs) == 1 && addrs[0] == "" {
 		addrs = nil
 		srvs := make([]*gorums.Server, 0, 4)
 		for i := 0; i < 4; i++ {
-			srv, addr := startServer("127.0.0.1:0")
+			srv, addr := startServer("127.0.0.1:0", keys.public())
 			srvs = append(srvs, srv)
 			addrs = append(addrs, addr)
 			log.Printf("Started storage server on %s\n", addr)
@@ -36,7 +48,7 @@ func main() {
 		}()
 	}
 
-	client := newClient(addrs)
+	client := newClient(addrs, keys)
 	log.Println("Started client")
 	Repl(client)
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"reconfstorage/proto"
+	"reconfstorage/qf"
+
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// acceptor is the Paxos state of a server for one slot, i.e. for the successor of one configuration.
+type acceptor struct {
+	promised       uint64
+	acceptedBallot uint64
+	accepted       *proto.MetaConfig
+}
+
+// ballot returns the ballot of the client in the given round.
+// The proposer ID in the lower bits makes the ballots of different clients unique.
+func (c *client) ballot(round uint32) uint64 {
+	return uint64(round)<<32 | uint64(c.proposer)
+}
+
+// ballotRound returns the round of a ballot.
+func ballotRound(ballot uint64) uint32 {
+	return uint32(ballot >> 32)
+}
+
+// errSlotClosed is returned by a server for Paxos requests in the slot of a configuration
+// that is older than a started configuration; its successor has been chosen and started.
+var errSlotClosed = errors.New("slot of a superseded configuration")
+
+// errOtherChosen is returned by an attempt of reconfOrdered when the configuration of another client was chosen.
+var errOtherChosen = errors.New("another configuration was chosen")
+
+// reconfOrdered moves the storage to the configuration goal, like reconf,
+// but first lets the servers of the current configuration choose its successor with Paxos.
+// If the configuration of another client is chosen, it is installed,
+// and goal is proposed again as the successor of that configuration.
+// The attempts follow the client's retry policy.
+// This gives a single chain of configurations, as long as all clients reconfigure this way.
+func (c *client) reconfOrdered(goal *proto.MetaConfig) error {
+	policy := c.retry
+	policy.Retryable = func(err error) bool { return errors.Is(err, errOtherChosen) }
+	return policy.do(func() error {
+		cur := c.current()
+		value := pb.Clone(goal).(*proto.MetaConfig)
+		value.Started = false
+		value.Time = timestampAfter(cur.GetTime())
+
+		chosen, err := c.propose(cur, value)
+		if err != nil {
+			// the servers of cur may have been decommissioned by newer configurations
+			if c.refresh() == nil && c.current() != cur {
+				return fmt.Errorf("propose %q after %q: %w", goal.GetAdds(), cur.GetAdds(), errOtherChosen)
+			}
+			return fmt.Errorf("propose configuration: %w", err)
+		}
+		err = c.install(chosen)
+		switch {
+		case errors.Is(err, ErrConfigSuperseded):
+			// another client has moved on past chosen; learn where from the servers
+			if err := c.refresh(); err != nil {
+				return err
+			}
+			// value may have been superseded before it was started
+			if pb.Equal(chosen, value) {
+				return err
+			}
+		case err != nil:
+			return err
+		}
+		if !pb.Equal(chosen, value) {
+			return fmt.Errorf("propose %q after %q: %w", goal.GetAdds(), chosen.GetAdds(), errOtherChosen)
+		}
+		return nil
+	})
+}
+
+// refresh moves the client to the newest started configuration known to the servers,
+// by reading from the current configuration and following its successors.
+func (c *client) refresh() error {
+	_, err := c.readFrom("", proto.Consistency_MAJORITY, c.current(), nil)
+	return err
+}
+
+// timestampAfter returns the timestamp of a successor of the configuration with timestamp ts:
+// the current time, or a time just after ts if the clock is behind ts.
+// It is never a whole number of latticeSteps after ts, which are the timestamps of the lattice, see latticeConfig.
+func timestampAfter(ts *timestamppb.Timestamp) *timestamppb.Timestamp {
+	now := time.Now()
+	if !now.After(ts.AsTime()) {
+		now = ts.AsTime().Add(time.Nanosecond)
+	}
+	if now.Sub(ts.AsTime())%latticeStep == 0 {
+		now = now.Add(time.Nanosecond)
+	}
+	return timestamppb.New(now)
+}
+
+// propose runs single-decree Paxos among the servers of the started configuration conf
+// to choose its successor. It returns the chosen configuration,
+// which is value unless another value was accepted before.
+// Competing proposers are preempted by higher ballots, and retry with the client's retry policy.
+func (c *client) propose(conf, value *proto.MetaConfig) (chosen *proto.MetaConfig, err error) {
+	cfg, err := c.parseConfiguration(conf)
+	if err != nil {
+		return nil, err
+	}
+	round := uint32(1)
+	err = c.retry.do(func() error {
+		ballot := c.ballot(round)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		promise, err := cfg.PrepareQC(ctx, &proto.PrepareRequest{Slot: conf.GetTime(), Ballot: ballot})
+		if err != nil {
+			return quorumError("PrepareQC", err)
+		}
+		if !promise.GetOK() {
+			round = ballotRound(promise.GetBallot()) + 1
+			return preempted("PrepareQC", ballot)
+		}
+		// a value that may have been chosen must be proposed again
+		proposal := value
+		if promise.GetAccepted() != nil {
+			proposal = promise.GetAccepted()
+		}
+		accepted, err := cfg.AcceptQC(ctx, &proto.AcceptRequest{Slot: conf.GetTime(), Ballot: ballot, Value: proposal})
+		if err != nil {
+			return quorumError("AcceptQC", err)
+		}
+		if !accepted.GetOK() {
+			round = ballotRound(accepted.GetBallot()) + 1
+			return preempted("AcceptQC", ballot)
+		}
+		chosen = proposal
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chosen, nil
+}
+
+// preempted returns the error of a Paxos phase that was rejected because of a higher ballot.
+func preempted(method string, ballot uint64) error {
+	return &QuorumError{Method: method, Reason: fmt.Sprintf("ballot %d preempted", ballot)}
+}
+
+// ballotReply is a Paxos reply carrying the highest ballot promised by the server.
+type ballotReply interface {
+	GetBallot() uint64
+}
+
+// rejected returns a reply that is not OK, with the highest ballot promised by the servers.
+func rejected[Req any, Resp ballotReply](reply func(ballot uint64) Resp) func(Req, map[uint32]Resp) Resp {
+	return func(_ Req, replies map[uint32]Resp) Resp {
+		var highest uint64
+		for _, r := range replies {
+			if r.GetBallot() > highest {
+				highest = r.GetBallot()
+			}
+		}
+		return reply(highest)
+	}
+}
+
+// promised returns the value accepted with the highest ballot by the servers that promised.
+func promised(_ *proto.PrepareRequest, replies map[uint32]*proto.PromiseResponse) *proto.PromiseResponse {
+	resp := &proto.PromiseResponse{OK: true}
+	for _, r := range qf.Filter(replies, (*proto.PromiseResponse).GetOK) {
+		if r.GetAccepted() != nil && r.GetAcceptedBallot() > resp.GetAcceptedBallot() {
+			resp.AcceptedBallot = r.GetAcceptedBallot()
+			resp.Accepted = r.GetAccepted()
+		}
+	}
+	return resp
+}
+
+// acceptor returns the Paxos state of the slot of the configuration with the given timestamp.
+// The caller must hold s.mut.
+func (s *storageServer) acceptor(slot time.Time) *acceptor {
+	a, ok := s.acceptors[slot]
+	if !ok {
+		a = &acceptor{}
+		s.acceptors[slot] = a
+	}
+	return a
+}
+
+// Prepare promises to ignore ballots lower than the requested one in the slot,
+// and returns the value accepted in the slot.
+func (s *storageServer) Prepare(req *proto.PrepareRequest) (*proto.PromiseResponse, error) {
+	s.logger.Printf("Prepare ballot %d\n", req.GetBallot())
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.decommissioned {
+		return nil, errDecommissioned
+	}
+	if s.fenced(req.GetSlot()) {
+		return nil, errSlotClosed
+	}
+	a := s.acceptor(req.GetSlot().AsTime())
+	if req.GetBallot() < a.promised {
+		return &proto.PromiseResponse{OK: false, Ballot: a.promised}, nil
+	}
+	a.promised = req.GetBallot()
+	return &proto.PromiseResponse{OK: true, Ballot: a.promised, AcceptedBallot: a.acceptedBallot, Accepted: a.accepted}, nil
+}
+
+// Accept accepts the requested value in the slot, unless a higher ballot was promised.
+func (s *storageServer) Accept(req *proto.AcceptRequest) (*proto.AcceptResponse, error) {
+	s.logger.Printf("Accept ballot %d: '%s'\n", req.GetBallot(), req.GetValue().GetAdds())
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.decommissioned {
+		return nil, errDecommissioned
+	}
+	if s.fenced(req.GetSlot()) {
+		return nil, errSlotClosed
+	}
+	a := s.acceptor(req.GetSlot().AsTime())
+	if req.GetBallot() < a.promised {
+		return &proto.AcceptResponse{OK: false, Ballot: a.promised}, nil
+	}
+	a.promised = req.GetBallot()
+	a.acceptedBallot = req.GetBallot()
+	a.accepted = req.GetValue()
+	return &proto.AcceptResponse{OK: true, Ballot: a.promised}, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestServer returns a storage server that does not log.
+func newTestServer() *storageServer {
+	s := newStorageServer()
+	s.logger = log.New(io.Discard, "", 0)
+	return s
+}
+
+func TestAcceptor(t *testing.T) {
+	s := newTestServer()
+	slot := timestamppb.New(time.Unix(1, 0))
+	value := &proto.MetaConfig{Adds: "0:3"}
+
+	if p, _ := s.Prepare(&proto.PrepareRequest{Slot: slot, Ballot: 2}); !p.GetOK() || p.GetAccepted() != nil {
+		t.Fatalf("first prepare: %v", p)
+	}
+	if p, _ := s.Prepare(&proto.PrepareRequest{Slot: slot, Ballot: 1}); p.GetOK() || p.GetBallot() != 2 {
+		t.Errorf("prepare with a lower ballot: %v, want rejected with ballot 2", p)
+	}
+	if a, _ := s.Accept(&proto.AcceptRequest{Slot: slot, Ballot: 1, Value: value}); a.GetOK() {
+		t.Error("accepted a ballot lower than the promise")
+	}
+	if a, _ := s.Accept(&proto.AcceptRequest{Slot: slot, Ballot: 2, Value: value}); !a.GetOK() {
+		t.Error("did not accept the promised ballot")
+	}
+	// a later proposer learns the accepted value
+	p, _ := s.Prepare(&proto.PrepareRequest{Slot: slot, Ballot: 3})
+	if !p.GetOK() || p.GetAcceptedBallot() != 2 || p.GetAccepted().GetAdds() != "0:3" {
+		t.Errorf("prepare after accept: %v, want the value accepted with ballot 2", p)
+	}
+	// slots are independent
+	other := timestamppb.New(time.Unix(2, 0))
+	if p, _ := s.Prepare(&proto.PrepareRequest{Slot: other, Ballot: 1}); !p.GetOK() || p.GetAccepted() != nil {
+		t.Errorf("prepare in another slot: %v", p)
+	}
+}
+
+func TestPrepareQCQF(t *testing.T) {
+	q := newTestQSpec(t, 3)
+	in := &proto.PrepareRequest{Ballot: 5}
+	older := &proto.MetaConfig{Adds: "0:2"}
+	newer := &proto.MetaConfig{Adds: "1:3"}
+
+	if _, done := q.PrepareQCQF(in, map[uint32]*proto.PromiseResponse{1: {OK: true}}); done {
+		t.Error("done with one promise of three")
+	}
+	resp, done := q.PrepareQCQF(in, map[uint32]*proto.PromiseResponse{
+		1: {OK: true, AcceptedBallot: 1, Accepted: older},
+		2: {OK: true, AcceptedBallot: 3, Accepted: newer},
+	})
+	if !done || !resp.GetOK() || resp.GetAccepted().GetAdds() != "1:3" {
+		t.Errorf("two promises: %v, %v; want the value with the highest ballot", resp, done)
+	}
+	resp, done = q.PrepareQCQF(in, map[uint32]*proto.PromiseResponse{
+		1: {OK: false, Ballot: 7},
+		2: {OK: false, Ballot: 6},
+	})
+	if !done || resp.GetOK() || resp.GetBallot() != 7 {
+		t.Errorf("two rejections: %v, %v; want rejected with ballot 7", resp, done)
+	}
+}
+
+func TestAcceptorsPruned(t *testing.T) {
+	s := newTestServer()
+	old, next := configAt(1), configAt(2)
+	if p, _ := s.Prepare(&proto.PrepareRequest{Slot: old.GetTime(), Ballot: 1}); !p.GetOK() {
+		t.Fatalf("prepare: %v", p)
+	}
+	started := &proto.MetaConfig{Adds: next.GetAdds(), Time: next.GetTime(), Started: true}
+	if _, err := s.WriteConfig(started); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.acceptors[old.GetTime().AsTime()]; ok {
+		t.Error("the acceptor of the older configuration was kept")
+	}
+	// late requests for the slot of the older configuration do not bring it back
+	if _, err := s.Prepare(&proto.PrepareRequest{Slot: old.GetTime(), Ballot: 2}); err == nil {
+		t.Error("prepare in the slot of the older configuration: got no error")
+	}
+	if _, err := s.Accept(&proto.AcceptRequest{Slot: old.GetTime(), Ballot: 2, Value: configAt(3)}); err == nil {
+		t.Error("accept in the slot of the older configuration: got no error")
+	}
+	if _, ok := s.acceptors[old.GetTime().AsTime()]; ok {
+		t.Error("a late request for the older configuration created an acceptor")
+	}
+	// the successor of the started configuration can still be chosen
+	if p, _ := s.Prepare(&proto.PrepareRequest{Slot: next.GetTime(), Ballot: 1}); !p.GetOK() {
+		t.Errorf("prepare in the slot of the started configuration: %v", p)
+	}
+}
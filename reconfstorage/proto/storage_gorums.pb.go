@@ -144,6 +144,44 @@ type Node struct {
 	*gorums.RawNode
 }
 
+// ReadQCAsync executes the Read Quorum Call asynchronously
+// and returns a future for the most recent value.
+func (c *Configuration) ReadQCAsync(ctx context.Context, in *ReadRequest) *AsyncReadResponse {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.ReadQCAsync",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*ReadResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*ReadResponse)
+		}
+		return c.qspec.ReadQCAsyncQF(req.(*ReadRequest), r)
+	}
+
+	fut := c.RawConfiguration.AsyncCall(ctx, cd)
+	return &AsyncReadResponse{fut}
+}
+
+// WriteQCAsync executes the Write Quorum Call asynchronously
+// and returns a future for the result of the write.
+func (c *Configuration) WriteQCAsync(ctx context.Context, in *WriteRequest) *AsyncWriteResponse {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.WriteQCAsync",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*WriteResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*WriteResponse)
+		}
+		return c.qspec.WriteQCAsyncQF(req.(*WriteRequest), r)
+	}
+
+	fut := c.RawConfiguration.AsyncCall(ctx, cd)
+	return &AsyncWriteResponse{fut}
+}
+
 // Reference imports to suppress errors if they are not otherwise used.
 var _ empty.Empty
 
@@ -176,6 +214,20 @@ type QuorumSpec interface {
 	// you should implement your quorum function with '_ *WriteRequest'.
 	WriteQCQF(in *WriteRequest, replies map[uint32]*WriteResponse) (*WriteResponse, bool)
 
+	// ReadQCAsyncQF is the quorum function for the ReadQCAsync
+	// asynchronous quorum call method. The in parameter is the request object
+	// supplied to the ReadQCAsync method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *ReadRequest'.
+	ReadQCAsyncQF(in *ReadRequest, replies map[uint32]*ReadResponse) (*ReadResponse, bool)
+
+	// WriteQCAsyncQF is the quorum function for the WriteQCAsync
+	// asynchronous quorum call method. The in parameter is the request object
+	// supplied to the WriteQCAsync method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *WriteRequest'.
+	WriteQCAsyncQF(in *WriteRequest, replies map[uint32]*WriteResponse) (*WriteResponse, bool)
+
 	// ListKeysQCQF is the quorum function for the ListKeysQC
 	// quorum call method. The in parameter is the request object
 	// supplied to the ListKeysQC method at call time, and may or may not
@@ -189,6 +241,69 @@ type QuorumSpec interface {
 	// be used by the quorum function. If the in parameter is not needed
 	// you should implement your quorum function with '_ *MetaConfig'.
 	WriteMetaConfQCQF(in *MetaConfig, replies map[uint32]*WriteResponse) (*WriteResponse, bool)
+
+	// PreWriteQCQF is the quorum function for the PreWriteQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the PreWriteQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *WriteRequest'.
+	PreWriteQCQF(in *WriteRequest, replies map[uint32]*WriteResponse) (*WriteResponse, bool)
+
+	// FinalizeQCQF is the quorum function for the FinalizeQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the FinalizeQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *WriteRequest'.
+	FinalizeQCQF(in *WriteRequest, replies map[uint32]*WriteResponse) (*WriteResponse, bool)
+
+	// ReadFragmentQCQF is the quorum function for the ReadFragmentQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the ReadFragmentQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *ReadRequest'.
+	ReadFragmentQCQF(in *ReadRequest, replies map[uint32]*ReadResponse) (*ReadResponse, bool)
+
+	// PrepareQCQF is the quorum function for the PrepareQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the PrepareQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *PrepareRequest'.
+	PrepareQCQF(in *PrepareRequest, replies map[uint32]*PromiseResponse) (*PromiseResponse, bool)
+
+	// AcceptQCQF is the quorum function for the AcceptQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the AcceptQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *AcceptRequest'.
+	AcceptQCQF(in *AcceptRequest, replies map[uint32]*AcceptResponse) (*AcceptResponse, bool)
+
+	// ProposeQCQF is the quorum function for the ProposeQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the ProposeQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *LatticeRequest'.
+	ProposeQCQF(in *LatticeRequest, replies map[uint32]*LatticeResponse) (*LatticeResponse, bool)
+
+	// ProgressQCQF is the quorum function for the ProgressQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the ProgressQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *Progress'.
+	ProgressQCQF(in *Progress, replies map[uint32]*Progress) (*Progress, bool)
+
+	// PullStateQCQF is the quorum function for the PullStateQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the PullStateQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *PullRequest'.
+	PullStateQCQF(in *PullRequest, replies map[uint32]*PullResponse) (*PullResponse, bool)
+
+	// TransferStateQCQF is the quorum function for the TransferStateQC
+	// quorum call method. The in parameter is the request object
+	// supplied to the TransferStateQC method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *TransferRequest'.
+	TransferStateQCQF(in *TransferRequest, replies map[uint32]*TransferBatch) (*TransferBatch, bool)
 }
 
 // ReadQC executes the Read Quorum Call on a configuration
@@ -279,6 +394,205 @@ func (c *Configuration) WriteMetaConfQC(ctx context.Context, in *MetaConfig) (re
 	return res.(*WriteResponse), err
 }
 
+// PreWriteQC stores a fragment of an erasure-coded value on each Node.
+// The fragment is not read until it is finalized.
+func (c *Configuration) PreWriteQC(ctx context.Context, in *WriteRequest, f func(*WriteRequest, uint32) *WriteRequest) (resp *WriteResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.PreWriteQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*WriteResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*WriteResponse)
+		}
+		return c.qspec.PreWriteQCQF(req.(*WriteRequest), r)
+	}
+	cd.PerNodeArgFn = func(req protoreflect.ProtoMessage, nid uint32) protoreflect.ProtoMessage {
+		return f(req.(*WriteRequest), nid)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*WriteResponse), err
+}
+
+// FinalizeQC marks the erasure-coded value with the given Key and Time as finalized.
+func (c *Configuration) FinalizeQC(ctx context.Context, in *WriteRequest) (resp *WriteResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.FinalizeQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*WriteResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*WriteResponse)
+		}
+		return c.qspec.FinalizeQCQF(req.(*WriteRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*WriteResponse), err
+}
+
+// ReadFragmentQC returns the Time of the newest finalized erasure-coded value if the request has no Time.
+// Otherwise it finalizes the value with the requested Time and returns the value decoded from the fragments.
+func (c *Configuration) ReadFragmentQC(ctx context.Context, in *ReadRequest) (resp *ReadResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.ReadFragmentQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*ReadResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*ReadResponse)
+		}
+		return c.qspec.ReadFragmentQCQF(req.(*ReadRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ReadResponse), err
+}
+
+// PrepareQC is the first phase of single-decree Paxos on the successor of a configuration.
+// Nodes promise to ignore lower ballots and return the value they have accepted, if any.
+func (c *Configuration) PrepareQC(ctx context.Context, in *PrepareRequest) (resp *PromiseResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.PrepareQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*PromiseResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*PromiseResponse)
+		}
+		return c.qspec.PrepareQCQF(req.(*PrepareRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*PromiseResponse), err
+}
+
+// AcceptQC is the second phase of single-decree Paxos on the successor of a configuration.
+func (c *Configuration) AcceptQC(ctx context.Context, in *AcceptRequest) (resp *AcceptResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.AcceptQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*AcceptResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*AcceptResponse)
+		}
+		return c.qspec.AcceptQCQF(req.(*AcceptRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*AcceptResponse), err
+}
+
+// ProposeQC proposes a set of changes in generalized lattice agreement, see lattice.go.
+// Nodes accept the proposal if it includes their accepted changes, and otherwise return them.
+func (c *Configuration) ProposeQC(ctx context.Context, in *LatticeRequest) (resp *LatticeResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.ProposeQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*LatticeResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*LatticeResponse)
+		}
+		return c.qspec.ProposeQCQF(req.(*LatticeRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*LatticeResponse), err
+}
+
+// ProgressQC stores the progress of a reconfiguration on the servers of the new configuration,
+// and returns the furthest progress they know, see jobs.go.
+func (c *Configuration) ProgressQC(ctx context.Context, in *Progress) (resp *Progress, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.ProgressQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*Progress, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*Progress)
+		}
+		return c.qspec.ProgressQCQF(req.(*Progress), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Progress), err
+}
+
+// PullStateQC asks the servers of a new configuration to pull the state
+// from the old configurations themselves, see transfer.go.
+func (c *Configuration) PullStateQC(ctx context.Context, in *PullRequest) (resp *PullResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.PullStateQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*PullResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*PullResponse)
+		}
+		return c.qspec.PullStateQCQF(req.(*PullRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*PullResponse), err
+}
+
+// TransferStateQC sends the next batch of the values of a server, sorted by key, to a server that pulls the state.
+// It is called on one old server at a time, which is asked for batch after batch, see transfer.go.
+func (c *Configuration) TransferStateQC(ctx context.Context, in *TransferRequest) (resp *TransferBatch, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "storage.Storage.TransferStateQC",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*TransferBatch, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*TransferBatch)
+		}
+		return c.qspec.TransferStateQCQF(req.(*TransferRequest), r)
+	}
+
+	res, err := c.RawConfiguration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*TransferBatch), err
+}
+
 // ReadRPC executes the Read RPC on a single Node
 func (n *Node) ReadRPC(ctx context.Context, in *ReadRequest) (resp *ReadResponse, err error) {
 	cd := gorums.CallData{
@@ -322,16 +636,43 @@ func (n *Node) ListKeysRPC(ctx context.Context, in *ListRequest) (resp *ListResp
 	return res.(*ListResponse), err
 }
 
+// Decommission tells a server that was removed by the started configuration in the tombstone
+// that it is no longer needed. The server drops its data and redirects later requests.
+func (n *Node) Decommission(ctx context.Context, in *Tombstone) (resp *empty.Empty, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "storage.Storage.Decommission",
+	}
+
+	res, err := n.RawNode.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*empty.Empty), err
+}
+
 // Storage is the server-side API for the Storage Service
 type Storage interface {
 	ReadRPC(ctx gorums.ServerCtx, request *ReadRequest) (response *ReadResponse, err error)
 	WriteRPC(ctx gorums.ServerCtx, request *WriteRequest) (response *WriteResponse, err error)
 	ReadQC(ctx gorums.ServerCtx, request *ReadRequest) (response *ReadResponse, err error)
 	WriteQC(ctx gorums.ServerCtx, request *WriteRequest) (response *WriteResponse, err error)
+	ReadQCAsync(ctx gorums.ServerCtx, request *ReadRequest) (response *ReadResponse, err error)
+	WriteQCAsync(ctx gorums.ServerCtx, request *WriteRequest) (response *WriteResponse, err error)
 	WriteMulticast(ctx gorums.ServerCtx, request *WriteRequest)
 	ListKeysRPC(ctx gorums.ServerCtx, request *ListRequest) (response *ListResponse, err error)
+	Decommission(ctx gorums.ServerCtx, request *Tombstone) (response *empty.Empty, err error)
 	ListKeysQC(ctx gorums.ServerCtx, request *ListRequest) (response *ListResponse, err error)
 	WriteMetaConfQC(ctx gorums.ServerCtx, request *MetaConfig) (response *WriteResponse, err error)
+	PreWriteQC(ctx gorums.ServerCtx, request *WriteRequest) (response *WriteResponse, err error)
+	FinalizeQC(ctx gorums.ServerCtx, request *WriteRequest) (response *WriteResponse, err error)
+	ReadFragmentQC(ctx gorums.ServerCtx, request *ReadRequest) (response *ReadResponse, err error)
+	PrepareQC(ctx gorums.ServerCtx, request *PrepareRequest) (response *PromiseResponse, err error)
+	AcceptQC(ctx gorums.ServerCtx, request *AcceptRequest) (response *AcceptResponse, err error)
+	ProposeQC(ctx gorums.ServerCtx, request *LatticeRequest) (response *LatticeResponse, err error)
+	ProgressQC(ctx gorums.ServerCtx, request *Progress) (response *Progress, err error)
+	PullStateQC(ctx gorums.ServerCtx, request *PullRequest) (response *PullResponse, err error)
+	TransferStateQC(ctx gorums.ServerCtx, request *TransferRequest) (response *TransferBatch, err error)
 }
 
 func RegisterStorageServer(srv *gorums.Server, impl Storage) {
@@ -359,6 +700,18 @@ func RegisterStorageServer(srv *gorums.Server, impl Storage) {
 		resp, err := impl.WriteQC(ctx, req)
 		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
 	})
+	srv.RegisterHandler("storage.Storage.ReadQCAsync", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*ReadRequest)
+		defer ctx.Release()
+		resp, err := impl.ReadQCAsync(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.WriteQCAsync", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*WriteRequest)
+		defer ctx.Release()
+		resp, err := impl.WriteQCAsync(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
 	srv.RegisterHandler("storage.Storage.WriteMulticast", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
 		req := in.Message.(*WriteRequest)
 		defer ctx.Release()
@@ -370,6 +723,12 @@ func RegisterStorageServer(srv *gorums.Server, impl Storage) {
 		resp, err := impl.ListKeysRPC(ctx, req)
 		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
 	})
+	srv.RegisterHandler("storage.Storage.Decommission", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*Tombstone)
+		defer ctx.Release()
+		resp, err := impl.Decommission(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
 	srv.RegisterHandler("storage.Storage.ListKeysQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
 		req := in.Message.(*ListRequest)
 		defer ctx.Release()
@@ -382,6 +741,72 @@ func RegisterStorageServer(srv *gorums.Server, impl Storage) {
 		resp, err := impl.WriteMetaConfQC(ctx, req)
 		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
 	})
+	srv.RegisterHandler("storage.Storage.PreWriteQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*WriteRequest)
+		defer ctx.Release()
+		resp, err := impl.PreWriteQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.FinalizeQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*WriteRequest)
+		defer ctx.Release()
+		resp, err := impl.FinalizeQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.ReadFragmentQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*ReadRequest)
+		defer ctx.Release()
+		resp, err := impl.ReadFragmentQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.PrepareQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*PrepareRequest)
+		defer ctx.Release()
+		resp, err := impl.PrepareQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.AcceptQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*AcceptRequest)
+		defer ctx.Release()
+		resp, err := impl.AcceptQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.ProposeQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*LatticeRequest)
+		defer ctx.Release()
+		resp, err := impl.ProposeQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.ProgressQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*Progress)
+		defer ctx.Release()
+		resp, err := impl.ProgressQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.PullStateQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*PullRequest)
+		defer ctx.Release()
+		resp, err := impl.PullStateQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("storage.Storage.TransferStateQC", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*TransferRequest)
+		defer ctx.Release()
+		resp, err := impl.TransferStateQC(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+}
+
+type internalAcceptResponse struct {
+	nid   uint32
+	reply *AcceptResponse
+	err   error
+}
+
+type internalLatticeResponse struct {
+	nid   uint32
+	reply *LatticeResponse
+	err   error
 }
 
 type internalListResponse struct {
@@ -390,14 +815,68 @@ type internalListResponse struct {
 	err   error
 }
 
+type internalProgress struct {
+	nid   uint32
+	reply *Progress
+	err   error
+}
+
+type internalPromiseResponse struct {
+	nid   uint32
+	reply *PromiseResponse
+	err   error
+}
+
+type internalPullResponse struct {
+	nid   uint32
+	reply *PullResponse
+	err   error
+}
+
 type internalReadResponse struct {
 	nid   uint32
 	reply *ReadResponse
 	err   error
 }
 
+type internalTransferBatch struct {
+	nid   uint32
+	reply *TransferBatch
+	err   error
+}
+
 type internalWriteResponse struct {
 	nid   uint32
 	reply *WriteResponse
 	err   error
 }
+
+// AsyncReadResponse is a async object for processing replies.
+type AsyncReadResponse struct {
+	*gorums.Async
+}
+
+// Get returns the reply and any error associated with the called method.
+// The method blocks until a reply or error is available.
+func (f *AsyncReadResponse) Get() (*ReadResponse, error) {
+	resp, err := f.Async.Get()
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ReadResponse), err
+}
+
+// AsyncWriteResponse is a async object for processing replies.
+type AsyncWriteResponse struct {
+	*gorums.Async
+}
+
+// Get returns the reply and any error associated with the called method.
+// The method blocks until a reply or error is available.
+func (f *AsyncWriteResponse) Get() (*WriteResponse, error) {
+	resp, err := f.Async.Get()
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*WriteResponse), err
+}
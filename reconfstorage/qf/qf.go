@@ -0,0 +1,156 @@
+// Package qf provides generic building blocks for Gorums quorum functions.
+//
+// A quorum function is composed from a function that combines the replies into a result,
+// wrapped in the conditions that must hold before the result is returned:
+//
+//	f := qf.AbortIfImpossible(all, quorum, ok, abort,
+//		qf.Threshold(quorum, ok, qf.Combine(combine)))
+//
+// The helpers Newest, Union and Merge implement the usual ways of combining replies.
+package qf
+
+import (
+	"time"
+)
+
+// QF is a quorum function. It returns the result of the call and true once the replies suffice,
+// or false to wait for more replies.
+type QF[Req, Resp any] func(in Req, replies map[uint32]Resp) (Resp, bool)
+
+// Quorum reports whether a set of node IDs is a quorum.
+type Quorum func(ids map[uint32]bool) bool
+
+// AtLeast returns a Quorum of any n nodes.
+func AtLeast(n int) Quorum {
+	return func(ids map[uint32]bool) bool { return len(ids) >= n }
+}
+
+// Any counts every reply.
+func Any[T any](T) bool {
+	return true
+}
+
+// IDs returns the IDs of the nodes in replies.
+func IDs[T any](replies map[uint32]T) map[uint32]bool {
+	ids := make(map[uint32]bool, len(replies))
+	for id := range replies {
+		ids[id] = true
+	}
+	return ids
+}
+
+// Filter returns the replies for which ok returns true.
+func Filter[T any](replies map[uint32]T, ok func(T) bool) map[uint32]T {
+	filtered := make(map[uint32]T, len(replies))
+	for id, r := range replies {
+		if ok(r) {
+			filtered[id] = r
+		}
+	}
+	return filtered
+}
+
+// Threshold returns a quorum function that waits until the nodes whose replies satisfy ok form a quorum,
+// and then calls next.
+func Threshold[Req, Resp any](quorum Quorum, ok func(Resp) bool, next QF[Req, Resp]) QF[Req, Resp] {
+	return func(in Req, replies map[uint32]Resp) (Resp, bool) {
+		if !quorum(IDs(Filter(replies, ok))) {
+			var none Resp
+			return none, false
+		}
+		return next(in, replies)
+	}
+}
+
+// Impossible reports whether the replies that satisfy ok can no longer form a quorum,
+// even if every node of all that has not replied yet sends a reply that satisfies ok.
+func Impossible[T any](replies map[uint32]T, all []uint32, quorum Quorum, ok func(T) bool) bool {
+	possible := IDs(Filter(replies, ok))
+	for _, id := range all {
+		if _, replied := replies[id]; !replied {
+			possible[id] = true
+		}
+	}
+	return !quorum(possible)
+}
+
+// AbortIf returns a quorum function that returns abort(in, replies) as soon as cond holds,
+// and otherwise calls next.
+func AbortIf[Req, Resp any](cond func(Req, map[uint32]Resp) bool, abort func(Req, map[uint32]Resp) Resp, next QF[Req, Resp]) QF[Req, Resp] {
+	return func(in Req, replies map[uint32]Resp) (Resp, bool) {
+		if cond(in, replies) {
+			return abort(in, replies), true
+		}
+		return next(in, replies)
+	}
+}
+
+// AbortIfImpossible returns a quorum function that returns abort(in, replies) as soon as
+// the replies that satisfy ok can no longer form a quorum of the nodes in all, and otherwise calls next.
+func AbortIfImpossible[Req, Resp any](all []uint32, quorum Quorum, ok func(Resp) bool, abort func(Req, map[uint32]Resp) Resp, next QF[Req, Resp]) QF[Req, Resp] {
+	impossible := func(_ Req, replies map[uint32]Resp) bool {
+		return Impossible(replies, all, quorum, ok)
+	}
+	return AbortIf(impossible, abort, next)
+}
+
+// Newest returns the reply with the most recent timestamp, and false if there are no replies.
+// Of several replies with the same timestamp, an arbitrary one is returned.
+func Newest[T any](replies map[uint32]T, timestamp func(T) time.Time) (T, bool) {
+	var newest T
+	var newestTime time.Time
+	found := false
+	for _, r := range replies {
+		if t := timestamp(r); !found || t.After(newestTime) {
+			newest, newestTime, found = r, t, true
+		}
+	}
+	return newest, found
+}
+
+// Union returns the items that are reported by at least votes replies, in no particular order.
+// With votes 1, it is the union of the items of all replies.
+// Items that are repeated in a reply are counted once.
+func Union[T any, K comparable](replies map[uint32]T, items func(T) []K, votes int) []K {
+	count := make(map[K]int)
+	for _, r := range replies {
+		seen := make(map[K]bool)
+		for _, item := range items(r) {
+			if !seen[item] {
+				seen[item] = true
+				count[item]++
+			}
+		}
+	}
+	union := make([]K, 0, len(count))
+	for item, n := range count {
+		if n >= votes {
+			union = append(union, item)
+		}
+	}
+	return union
+}
+
+// Merge returns the items of all replies, such as the configurations they know of,
+// with one item for each key, in no particular order. Of several items with the same key, an arbitrary one is kept.
+func Merge[T, V any, K comparable](replies map[uint32]T, items func(T) []V, key func(V) K) []V {
+	merged := make(map[K]V)
+	for _, r := range replies {
+		for _, item := range items(r) {
+			merged[key(item)] = item
+		}
+	}
+	list := make([]V, 0, len(merged))
+	for _, item := range merged {
+		list = append(list, item)
+	}
+	return list
+}
+
+// Combine returns a quorum function that combines any replies into a result with combine.
+// It is the innermost function of a composed quorum function.
+func Combine[Req, Resp any](combine func(Req, map[uint32]Resp) Resp) QF[Req, Resp] {
+	return func(in Req, replies map[uint32]Resp) (Resp, bool) {
+		return combine(in, replies), true
+	}
+}
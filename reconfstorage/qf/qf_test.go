@@ -0,0 +1,177 @@
+package qf
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+type reply struct {
+	ok    bool
+	time  int64
+	items []string
+}
+
+func isOK(r reply) bool { return r.ok }
+
+func replyTime(r reply) time.Time { return time.Unix(r.time, 0) }
+
+func replyItems(r reply) []string { return r.items }
+
+// combineCount returns a reply holding the number of replies.
+func combineCount(_ string, replies map[uint32]reply) reply { return reply{time: int64(len(replies))} }
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAtLeast(t *testing.T) {
+	q := AtLeast(2)
+	if q(map[uint32]bool{1: true}) {
+		t.Error("one node is a quorum of two")
+	}
+	if !q(map[uint32]bool{1: true, 2: true}) {
+		t.Error("two nodes are not a quorum of two")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	replies := map[uint32]reply{1: {ok: true}, 2: {ok: false}, 3: {ok: true}}
+	got := IDs(Filter(replies, isOK))
+	if len(got) != 2 || !got[1] || !got[3] {
+		t.Errorf("Filter = %v, want nodes 1 and 3", got)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	f := Threshold(AtLeast(2), isOK, Combine(combineCount))
+	tests := []struct {
+		name    string
+		replies map[uint32]reply
+		done    bool
+	}{
+		{"none", map[uint32]reply{}, false},
+		{"one", map[uint32]reply{1: {ok: true}}, false},
+		{"two not ok", map[uint32]reply{1: {ok: true}, 2: {ok: false}}, false},
+		{"two ok", map[uint32]reply{1: {ok: true}, 2: {ok: true}}, true},
+	}
+	for _, test := range tests {
+		got, done := f("", test.replies)
+		if done != test.done {
+			t.Errorf("%s: done = %v, want %v", test.name, done, test.done)
+		}
+		if done && got.time != int64(len(test.replies)) {
+			t.Errorf("%s: result = %d, want %d", test.name, got.time, len(test.replies))
+		}
+	}
+}
+
+func TestImpossible(t *testing.T) {
+	all := []uint32{1, 2, 3}
+	tests := []struct {
+		name       string
+		replies    map[uint32]reply
+		impossible bool
+	}{
+		{"none", map[uint32]reply{}, false},
+		{"one rejected", map[uint32]reply{1: {ok: false}}, false},
+		{"two rejected", map[uint32]reply{1: {ok: false}, 2: {ok: false}}, true},
+		{"one of each", map[uint32]reply{1: {ok: true}, 2: {ok: false}}, false},
+		{"all replied", map[uint32]reply{1: {ok: true}, 2: {ok: false}, 3: {ok: false}}, true},
+	}
+	for _, test := range tests {
+		if got := Impossible(test.replies, all, AtLeast(2), isOK); got != test.impossible {
+			t.Errorf("%s: Impossible = %v, want %v", test.name, got, test.impossible)
+		}
+	}
+}
+
+func TestAbortIfImpossible(t *testing.T) {
+	all := []uint32{1, 2, 3}
+	f := AbortIfImpossible(all, AtLeast(2), isOK, func(string, map[uint32]reply) reply { return reply{time: -1} },
+		Threshold(AtLeast(2), isOK, Combine(combineCount)))
+
+	if _, done := f("", map[uint32]reply{1: {ok: false}}); done {
+		t.Error("aborted while a quorum is still possible")
+	}
+	if got, done := f("", map[uint32]reply{1: {ok: false}, 2: {ok: false}}); !done || got.time != -1 {
+		t.Errorf("f = %d, %v, want -1, true", got.time, done)
+	}
+	if got, done := f("", map[uint32]reply{1: {ok: true}, 2: {ok: false}, 3: {ok: true}}); !done || got.time != 3 {
+		t.Errorf("f = %d, %v, want 3, true", got.time, done)
+	}
+}
+
+func TestAbortIf(t *testing.T) {
+	f := AbortIf(func(in string, _ map[uint32]reply) bool { return in == "abort" },
+		func(string, map[uint32]reply) reply { return reply{time: -1} },
+		Threshold(AtLeast(1), Any[reply], Combine(combineCount)))
+
+	if got, done := f("abort", map[uint32]reply{}); !done || got.time != -1 {
+		t.Errorf("f = %d, %v, want -1, true", got.time, done)
+	}
+	if _, done := f("", map[uint32]reply{}); done {
+		t.Error("done without replies")
+	}
+	if got, done := f("", map[uint32]reply{1: {}}); !done || got.time != 1 {
+		t.Errorf("f = %d, %v, want 1, true", got.time, done)
+	}
+}
+
+func TestNewest(t *testing.T) {
+	if _, ok := Newest(map[uint32]reply{}, replyTime); ok {
+		t.Error("Newest of no replies is ok")
+	}
+	replies := map[uint32]reply{1: {time: 3}, 2: {time: 7}, 3: {time: 5}}
+	got, ok := Newest(replies, replyTime)
+	if !ok || got.time != 7 {
+		t.Errorf("Newest = %v, %v, want time 7", got, ok)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	replies := map[uint32]reply{
+		1: {items: []string{"a", "b", "b"}},
+		2: {items: []string{"b", "c"}},
+		3: {items: []string{"c", "d"}},
+	}
+	tests := []struct {
+		votes int
+		want  []string
+	}{
+		{1, []string{"a", "b", "c", "d"}},
+		{2, []string{"b", "c"}},
+		{3, nil},
+	}
+	for _, test := range tests {
+		if got := sorted(Union(replies, replyItems, test.votes)); !equal(got, test.want) {
+			t.Errorf("Union(%d) = %v, want %v", test.votes, got, test.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	replies := map[uint32]reply{
+		1: {items: []string{"a1", "b1"}},
+		2: {items: []string{"b2", "c1"}},
+		3: {},
+	}
+	// items are keyed by their first letter
+	got := sorted(Merge(replies, replyItems, func(item string) byte { return item[0] }))
+	if len(got) != 3 || got[0] != "a1" || got[1][0] != 'b' || got[2] != "c1" {
+		t.Errorf("Merge = %v, want one item each of a, b and c", got)
+	}
+}
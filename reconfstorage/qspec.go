@@ -1,11 +1,33 @@
 package main
 
 import (
+	"time"
+
 	"reconfstorage/proto"
+	"reconfstorage/qf"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type qspec struct {
 	cfgSize int
+	// ids are the node IDs of the configuration
+	ids []uint32
+	// system decides which sets of nodes are quorums
+	system quorumSystem
+	// faults is the number of Byzantine servers tolerated.
+	// With faults > 0, values and configurations are only accepted
+	// if they are vouched for by faults+1 servers or signed by a writer trusted by keys.
+	faults int
+	keys   *keyring
+	// known resolves the configuration digests in replies
+	known *knownConfigs
+}
+
+// newQSpec returns the quorum specification for a configuration using the given quorum system.
+// The quorum specification must be bound to the node IDs of the configuration before quorum calls are made.
+func newQSpec(n int, system quorumSystem, keys *keyring, known *knownConfigs) *qspec {
+	return &qspec{cfgSize: n, system: system, faults: faultTolerance(system), keys: keys, known: known}
 }
 
 // ReadQCQF is the quorum function for the ReadQC
@@ -13,13 +35,15 @@ type qspec struct {
 // supplied to the ReadQC method at call time, and may or may not
 // be used by the quorum function. If the in parameter is not needed
 // you should implement your quorum function with '_ *ReadRequest'.
-func (q qspec) ReadQCQF(_ *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, bool) {
-	// wait until at least half of the replicas have responded
-	if len(replies) <= q.cfgSize/2 {
-		return nil, false
+func (q qspec) ReadQCQF(in *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, bool) {
+	replies = mergedReplies(in, replies)
+	// wait until enough replicas for the requested consistency level have responded,
+	// and return the value with the most recent timestamp
+	fenced := func(_ *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) *proto.ReadResponse {
+		return &proto.ReadResponse{Fenced: true, MConfigs: combineMConfs(q, replies)}
 	}
-	// return the value with the most recent timestamp
-	return newestValue(replies), true
+	return qf.AbortIf(supersededBy[*proto.ReadRequest, *proto.ReadResponse](q, in.GetConfigTime()), fenced,
+		qf.Threshold(q.levelQuorum(in.GetConsistency(), q.system.isReadQuorum), notFenced[*proto.ReadResponse], q.readResult))(in, replies)
 }
 
 // WriteQCQF is the quorum function for the WriteQC
@@ -28,120 +52,377 @@ func (q qspec) ReadQCQF(_ *proto.ReadRequest, replies map[uint32]*proto.ReadResp
 // be used by the quorum function. If the in parameter is not needed
 // you should implement your quorum function with '_ *WriteRequest'.
 func (q qspec) WriteQCQF(in *proto.WriteRequest, replies map[uint32]*proto.WriteResponse) (*proto.WriteResponse, bool) {
-	// wait until at least half of the replicas have responded and have updated their value
-	if numUpdated(replies) <= q.cfgSize/2 {
-		// if all replicas have responded, there must have been another write before ours
-		// that had a newer timestamp
-		if len(replies) == q.cfgSize {
-			return &proto.WriteResponse{New: false, MConfigs: writeCombineMConfs(replies)}, true
+	replies = mergedReplies(in, replies)
+	// wait until enough replicas for the requested consistency level have responded and have updated their value
+	return writeQF[*proto.WriteRequest](q, q.levelQuorum(in.GetConsistency(), q.system.isWriteQuorum), in.GetConfigTime())(in, replies)
+}
+
+// ReadQCAsyncQF is the quorum function for the ReadQCAsync
+// asynchronous quorum call. It is the same as ReadQCQF.
+func (q qspec) ReadQCAsyncQF(in *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, bool) {
+	return q.ReadQCQF(in, replies)
+}
+
+// WriteQCAsyncQF is the quorum function for the WriteQCAsync
+// asynchronous quorum call. It is the same as WriteQCQF.
+func (q qspec) WriteQCAsyncQF(in *proto.WriteRequest, replies map[uint32]*proto.WriteResponse) (*proto.WriteResponse, bool) {
+	return q.WriteQCQF(in, replies)
+}
+
+// readLevel returns the newest value of the replies that are not fenced, and its level:
+// LevelFirst for the first reply, LevelMajority once a read quorum has replied,
+// and LevelAll when all replicas have replied. The level is 0 if no value is known yet.
+func (q qspec) readLevel(in *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, int) {
+	current := qf.Filter(replies, notFenced[*proto.ReadResponse])
+	if len(current) == 0 {
+		return nil, 0
+	}
+	resp := q.newestValue(in.GetKey(), current)
+	if resp == nil {
+		if len(current) < q.cfgSize {
+			return nil, 0
 		}
-		return nil, false
+		resp = &proto.ReadResponse{OK: false}
+	}
+	resp.MConfigs = combineMConfs(q, replies)
+	level := LevelFirst
+	switch {
+	case len(current) == q.cfgSize:
+		level = LevelAll
+	case q.system.isReadQuorum(qf.IDs(current)):
+		level = LevelMajority
 	}
-	return &proto.WriteResponse{New: true, MConfigs: writeCombineMConfs(replies)}, true
+	return resp, level
 }
 
+// ListKeysQCQF is the quorum function for the ListKeysQC
+// quorum call. It returns the union of the keys of the replicas,
+// or with Byzantine servers, the keys reported by at least faults+1 replicas.
 func (q qspec) ListKeysQCQF(in *proto.ListRequest, replies map[uint32]*proto.ListResponse) (*proto.ListResponse, bool) {
-	if len(replies) <= q.cfgSize/2 {
-		return nil, false
+	fenced := func(_ *proto.ListRequest, replies map[uint32]*proto.ListResponse) *proto.ListResponse {
+		return &proto.ListResponse{Fenced: true, MConfigs: combineMConfs(q, replies)}
 	}
-	var keys map[string]bool
-	for _, resp := range replies {
-		if len(keys) == 0 {
-			keys = make(map[string]bool, len(resp.GetKeys()))
-		}
-		for _, k := range resp.GetKeys() {
-			keys[k] = true
+	return qf.AbortIf(supersededBy[*proto.ListRequest, *proto.ListResponse](q, in.GetConfigTime()), fenced,
+		qf.Threshold(q.levelQuorum(in.GetConsistency(), q.system.isReadQuorum), notFenced[*proto.ListResponse],
+			qf.Combine(func(_ *proto.ListRequest, replies map[uint32]*proto.ListResponse) *proto.ListResponse {
+				keys := qf.Union(qf.Filter(replies, notFenced[*proto.ListResponse]), (*proto.ListResponse).GetKeys, q.faults+1)
+				return &proto.ListResponse{Keys: keys, MConfigs: combineMConfs(q, replies)}
+			})))(in, replies)
+}
+
+// WriteMetaConfQCQF is the quorum function for the WriteMetaConfQC
+// quorum call. It is like WriteQCQF, but waits for a quorum of the quorum system's meta quorums.
+func (q qspec) WriteMetaConfQCQF(in *proto.MetaConfig, replies map[uint32]*proto.WriteResponse) (*proto.WriteResponse, bool) {
+	return writeQF[*proto.MetaConfig](q, q.system.isMetaQuorum, nil)(in, replies)
+}
+
+// PrepareQCQF is the quorum function for the PrepareQC
+// quorum call. It waits for promises from a meta quorum, since any two meta quorums intersect,
+// and returns the value accepted with the highest ballot. If a meta quorum can no longer promise,
+// it returns a reply that is not OK, with the highest ballot promised by the servers.
+func (q qspec) PrepareQCQF(in *proto.PrepareRequest, replies map[uint32]*proto.PromiseResponse) (*proto.PromiseResponse, bool) {
+	reject := rejected[*proto.PrepareRequest](func(ballot uint64) *proto.PromiseResponse { return &proto.PromiseResponse{Ballot: ballot} })
+	return qf.AbortIfImpossible(q.ids, q.system.isMetaQuorum, (*proto.PromiseResponse).GetOK, reject,
+		qf.Threshold(q.system.isMetaQuorum, (*proto.PromiseResponse).GetOK, qf.Combine(promised)))(in, replies)
+}
+
+// AcceptQCQF is the quorum function for the AcceptQC
+// quorum call. It is like PrepareQCQF: the value is chosen once a meta quorum has accepted it.
+func (q qspec) AcceptQCQF(in *proto.AcceptRequest, replies map[uint32]*proto.AcceptResponse) (*proto.AcceptResponse, bool) {
+	reject := rejected[*proto.AcceptRequest](func(ballot uint64) *proto.AcceptResponse { return &proto.AcceptResponse{Ballot: ballot} })
+	accepted := func(*proto.AcceptRequest, map[uint32]*proto.AcceptResponse) *proto.AcceptResponse {
+		return &proto.AcceptResponse{OK: true}
+	}
+	return qf.AbortIfImpossible(q.ids, q.system.isMetaQuorum, (*proto.AcceptResponse).GetOK, reject,
+		qf.Threshold(q.system.isMetaQuorum, (*proto.AcceptResponse).GetOK, qf.Combine(accepted)))(in, replies)
+}
+
+// ProposeQCQF is the quorum function for the ProposeQC
+// quorum call. It is like PrepareQCQF: the proposal is decided once a meta quorum has accepted it.
+// If a meta quorum can no longer accept it, it returns a reply that is not OK with the union of the changes accepted by the servers,
+// and the quorums of the lowest node that rejected the proposal's quorums.
+func (q qspec) ProposeQCQF(in *proto.LatticeRequest, replies map[uint32]*proto.LatticeResponse) (*proto.LatticeResponse, bool) {
+	reject := func(_ *proto.LatticeRequest, replies map[uint32]*proto.LatticeResponse) *proto.LatticeResponse {
+		resp := &proto.LatticeResponse{Accepted: joinChanges(qf.Merge(replies, (*proto.LatticeResponse).GetAccepted, changeKey))}
+		lowest := uint32(0)
+		for id, r := range replies {
+			if r.GetQuorums() != nil && (resp.Quorums == nil || id < lowest) {
+				lowest, resp.Quorums = id, r.GetQuorums()
+			}
 		}
+		return resp
 	}
-	allkeys := make([]string, 0, len(keys))
-	for k := range keys {
-		allkeys = append(allkeys, k)
+	accepted := func(*proto.LatticeRequest, map[uint32]*proto.LatticeResponse) *proto.LatticeResponse {
+		return &proto.LatticeResponse{OK: true}
 	}
-	return &proto.ListResponse{Keys: allkeys, MConfigs: listCombineMConfs(replies)}, true
+	return qf.AbortIfImpossible(q.ids, q.system.isMetaQuorum, (*proto.LatticeResponse).GetOK, reject,
+		qf.Threshold(q.system.isMetaQuorum, (*proto.LatticeResponse).GetOK, qf.Combine(accepted)))(in, replies)
 }
 
-func (q qspec) WriteMetaConfQCQF(in *proto.MetaConfig, replies map[uint32]*proto.WriteResponse) (*proto.WriteResponse, bool) {
-	if numUpdated(replies) <= q.cfgSize/2 {
-		// if all replicas have responded, there must have been another write before ours
-		// that had a newer timestamp
-		if len(replies) == q.cfgSize {
-			return &proto.WriteResponse{New: false, MConfigs: writeCombineMConfs(replies)}, true
+// ProgressQCQF is the quorum function for the ProgressQC
+// quorum call. It waits for a meta quorum, which intersects the meta quorums that stored earlier progress,
+// and returns the furthest progress of the replies.
+func (q qspec) ProgressQCQF(in *proto.Progress, replies map[uint32]*proto.Progress) (*proto.Progress, bool) {
+	return qf.Threshold(q.system.isMetaQuorum, qf.Any[*proto.Progress], qf.Combine(func(_ *proto.Progress, replies map[uint32]*proto.Progress) *proto.Progress {
+		var furthest *proto.Progress
+		for _, r := range replies {
+			furthest = advance(furthest, r)
 		}
-		return nil, false
+		return furthest
+	}))(in, replies)
+}
+
+// PullStateQCQF is the quorum function for the PullStateQC
+// quorum call. It waits until a write quorum of the new configuration has pulled the state,
+// and returns the number of values they received. Unless all of them are done,
+// the cursor is the smallest of their cursors, up to which all of them have pulled the keys.
+// It returns a fenced reply as soon as a server is fenced.
+func (q qspec) PullStateQCQF(in *proto.PullRequest, replies map[uint32]*proto.PullResponse) (*proto.PullResponse, bool) {
+	fenced := func(_ *proto.PullRequest, replies map[uint32]*proto.PullResponse) bool {
+		return len(qf.Filter(replies, (*proto.PullResponse).GetFenced)) > 0
+	}
+	abort := func(*proto.PullRequest, map[uint32]*proto.PullResponse) *proto.PullResponse {
+		return &proto.PullResponse{Fenced: true}
 	}
-	return &proto.WriteResponse{New: true, MConfigs: writeCombineMConfs(replies)}, true
+	return qf.AbortIf(fenced, abort,
+		qf.Threshold(q.system.isWriteQuorum, qf.Any[*proto.PullResponse], qf.Combine(func(_ *proto.PullRequest, replies map[uint32]*proto.PullResponse) *proto.PullResponse {
+			resp := &proto.PullResponse{Done: true}
+			for _, r := range replies {
+				resp.Received += r.GetReceived()
+				if r.GetDone() {
+					continue
+				}
+				if resp.GetDone() || r.GetCursor() < resp.GetCursor() {
+					resp.Cursor = r.GetCursor()
+				}
+				resp.Done = false
+			}
+			return resp
+		})))(in, replies)
 }
 
-// newestValue returns the reply that had the most recent timestamp
-func newestValue(values map[uint32]*proto.ReadResponse) *proto.ReadResponse {
-	if len(values) < 1 {
-		return nil
+// TransferStateQCQF is the quorum function for the TransferStateQC
+// quorum call. The call is sent to a single old server, see stream, and returns its batch.
+func (q qspec) TransferStateQCQF(_ *proto.TransferRequest, replies map[uint32]*proto.TransferBatch) (*proto.TransferBatch, bool) {
+	for _, r := range replies {
+		return r, true
 	}
-	var newest *proto.ReadResponse
-	for _, v := range values {
-		if newest == nil || v.GetTime().AsTime().After(newest.GetTime().AsTime()) {
-			newest = v
-		}
+	return nil, false
+}
+
+// PreWriteQCQF is the quorum function for the PreWriteQC
+// quorum call. It is the same as WriteQCQF.
+func (q qspec) PreWriteQCQF(in *proto.WriteRequest, replies map[uint32]*proto.WriteResponse) (*proto.WriteResponse, bool) {
+	return q.WriteQCQF(in, replies)
+}
+
+// FinalizeQCQF is the quorum function for the FinalizeQC
+// quorum call. It waits for a write quorum, unless the configuration is stopped by a newer one.
+func (q qspec) FinalizeQCQF(in *proto.WriteRequest, replies map[uint32]*proto.WriteResponse) (*proto.WriteResponse, bool) {
+	fenced := func(_ *proto.WriteRequest, replies map[uint32]*proto.WriteResponse) *proto.WriteResponse {
+		return &proto.WriteResponse{Fenced: true, MConfigs: combineMConfs(q, replies)}
 	}
-	newest.MConfigs = readCombineMConfs(values)
-	return newest
+	return qf.AbortIf(stoppedBy[*proto.WriteRequest](q, in.GetConfigTime()), fenced,
+		qf.Threshold(q.system.isWriteQuorum, notFenced[*proto.WriteResponse], qf.Combine(writeResult[*proto.WriteRequest](q, true))))(in, replies)
+}
+
+// ReadFragmentQCQF is the quorum function for the ReadFragmentQC
+// quorum call. Without a Time in the request, it returns the newest finalized Time of a read quorum.
+// With a Time, it waits for a read quorum and enough fragments of that Time, and returns the decoded value.
+// If all replicas have replied without enough fragments, it returns a reply that is not OK.
+// If the configuration is superseded, it returns a fenced reply.
+func (q qspec) ReadFragmentQCQF(in *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, bool) {
+	fenced := func(_ *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) *proto.ReadResponse {
+		return &proto.ReadResponse{Fenced: true, MConfigs: combineMConfs(q, replies)}
+	}
+	return qf.AbortIf(supersededBy[*proto.ReadRequest, *proto.ReadResponse](q, in.GetConfigTime()), fenced,
+		qf.Threshold(q.system.isReadQuorum, notFenced[*proto.ReadResponse], q.fragmentResult))(in, replies)
 }
 
-// numUpdated returns the number of replicas that updated their value
-func numUpdated(replies map[uint32]*proto.WriteResponse) int {
-	count := 0
+// fragmentResult returns the newest finalized Time, or the value decoded from the fragments of the Time in the request.
+func (q qspec) fragmentResult(in *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, bool) {
+	if in.GetTime() == nil {
+		newest, ok := qf.Newest(qf.Filter(replies, (*proto.ReadResponse).GetOK), readTime)
+		return &proto.ReadResponse{OK: ok, Time: newest.GetTime(), MConfigs: combineMConfs(q, replies)}, true
+	}
+	var frags []*fragmentData
+	var meta *proto.Fragment
 	for _, r := range replies {
-		if r.GetNew() {
-			count++
+		f := r.GetFragment()
+		if f == nil || !r.GetTime().AsTime().Equal(in.GetTime().AsTime()) {
+			continue
+		}
+		if meta == nil {
+			meta = f
 		}
+		frags = append(frags, &fragmentData{index: int(f.GetIndex()), data: f.GetData()})
+	}
+	if meta == nil || len(frags) < int(meta.GetNeeded()) {
+		if len(replies) < q.cfgSize {
+			return nil, false
+		}
+		return &proto.ReadResponse{OK: false, Time: in.GetTime(), MConfigs: combineMConfs(q, replies)}, true
+	}
+	value, err := decode(frags, int(meta.GetTotal()), int(meta.GetNeeded()), int(meta.GetSize()))
+	if err != nil {
+		return &proto.ReadResponse{OK: false, Time: in.GetTime(), MConfigs: combineMConfs(q, replies)}, true
 	}
-	return count
+	return &proto.ReadResponse{OK: true, Value: string(value), Time: in.GetTime(), MConfigs: combineMConfs(q, replies)}, true
 }
 
-func listCombineMConfs(replies map[uint32]*proto.ListResponse) []*proto.MetaConfig {
-	configlists := make([][]*proto.MetaConfig, len(replies))
-	for _, wr := range replies {
-		configlists = append(configlists, wr.GetMConfigs())
+// levelQuorum returns the sets of nodes that satisfy the consistency level.
+// The default level MAJORITY needs a quorum of the configuration's quorum system.
+func (q qspec) levelQuorum(level proto.Consistency, isQuorum qf.Quorum) qf.Quorum {
+	switch level {
+	case proto.Consistency_ONE:
+		return qf.AtLeast(1)
+	case proto.Consistency_ALL:
+		return qf.AtLeast(q.cfgSize)
 	}
-	return combineMConfs(configlists)
+	return isQuorum
 }
 
-func writeCombineMConfs(replies map[uint32]*proto.WriteResponse) []*proto.MetaConfig {
-	configlists := make([][]*proto.MetaConfig, len(replies))
-	for _, wr := range replies {
-		configlists = append(configlists, wr.GetMConfigs())
+// readResult returns the value with the most recent timestamp, ignoring fenced replies.
+// If no value is vouched for, it waits for all replicas and then returns a reply that is not OK.
+func (q qspec) readResult(in *proto.ReadRequest, replies map[uint32]*proto.ReadResponse) (*proto.ReadResponse, bool) {
+	resp := q.newestValue(in.GetKey(), qf.Filter(replies, notFenced[*proto.ReadResponse]))
+	if resp == nil {
+		// no value is vouched for yet; wait for more replies
+		if len(replies) < q.cfgSize {
+			return nil, false
+		}
+		resp = &proto.ReadResponse{OK: false}
 	}
-	return combineMConfs(configlists)
+	resp.MConfigs = combineMConfs(q, replies)
+	return resp, true
+}
+
+// writeQF returns the quorum function of a write to the configuration with timestamp ts
+// that needs isQuorum of the replicas to update their value.
+// If the configuration is stopped by a newer one, the reply is fenced. Otherwise, as soon as a quorum is impossible,
+// there must have been another write before ours that had a newer timestamp.
+// Fenced replies may still turn out to be ignored, so they do not make a quorum impossible.
+func writeQF[Req any](q qspec, isQuorum qf.Quorum, ts *timestamppb.Timestamp) qf.QF[Req, *proto.WriteResponse] {
+	isNew := (*proto.WriteResponse).GetNew
+	mayBeNew := func(r *proto.WriteResponse) bool { return r.GetNew() || r.GetFenced() }
+	fenced := func(_ Req, replies map[uint32]*proto.WriteResponse) *proto.WriteResponse {
+		return &proto.WriteResponse{Fenced: true, MConfigs: combineMConfs(q, replies)}
+	}
+	return qf.AbortIf(stoppedBy[Req](q, ts), fenced,
+		qf.AbortIfImpossible(q.ids, isQuorum, mayBeNew, writeResult[Req](q, false),
+			qf.Threshold(isQuorum, isNew, qf.Combine(writeResult[Req](q, true)))))
+}
+
+// writeResult returns a function that combines the replies to a write.
+func writeResult[Req any](q qspec, isNew bool) func(Req, map[uint32]*proto.WriteResponse) *proto.WriteResponse {
+	return func(_ Req, replies map[uint32]*proto.WriteResponse) *proto.WriteResponse {
+		return &proto.WriteResponse{New: isNew, MConfigs: combineMConfs(q, replies)}
+	}
+}
+
+// newestValue returns the reply that had the most recent timestamp, without combining the configurations.
+// With Byzantine servers, it returns the most recent value that is vouched for, or nil.
+func (q qspec) newestValue(key string, values map[uint32]*proto.ReadResponse) *proto.ReadResponse {
+	var newest *proto.ReadResponse
+	if q.faults > 0 {
+		newest = q.vouchedValue(key, values)
+	} else {
+		newest, _ = qf.Newest(values, readTime)
+	}
+	return newest
+}
+
+func readTime(r *proto.ReadResponse) time.Time {
+	return r.GetTime().AsTime()
 }
 
-func readCombineMConfs(replies map[uint32]*proto.ReadResponse) []*proto.MetaConfig {
-	configlists := make([][]*proto.MetaConfig, len(replies))
-	for _, rr := range replies {
-		configlists = append(configlists, rr.GetMConfigs())
+// mconfsReply is a reply that carries the configurations known to the replica,
+// or the digest of the client's configurations if the replica knows the same.
+type mconfsReply interface {
+	GetMConfigs() []*proto.MetaConfig
+	GetConfigDigest() []byte
+}
+
+// replyConfigs returns the configurations known to the replica that sent r.
+func replyConfigs[T mconfsReply](q qspec, r T) []*proto.MetaConfig {
+	if digest := r.GetConfigDigest(); len(digest) > 0 && q.known != nil {
+		configs, _ := q.known.lookup(digest)
+		return configs
 	}
-	return combineMConfs(configlists)
+	return r.GetMConfigs()
 }
 
-func combineMConfs(configlists [][]*proto.MetaConfig) []*proto.MetaConfig {
-	if len(configlists) == 0 {
+// combineMConfs returns the configurations known to the replicas, one for each timestamp,
+// or for configurations of the lattice, one for each set of changes, together with their join.
+// With Byzantine servers, only the configurations that are vouched for are returned.
+func combineMConfs[T mconfsReply](q qspec, replies map[uint32]T) []*proto.MetaConfig {
+	if len(replies) == 0 {
 		return nil
 	}
-	confs := make(map[struct {
-		int64
-		int32
-	}]*proto.MetaConfig, len(configlists[0]))
-	for _, list := range configlists {
-		for _, c := range list {
-			confs[struct {
-				int64
-				int32
-			}{c.GetTime().Seconds, c.GetTime().Nanos}] = c
+	if q.faults > 0 {
+		configlists := make([][]*proto.MetaConfig, 0, len(replies))
+		for _, r := range replies {
+			configlists = append(configlists, replyConfigs(q, r))
+		}
+		return q.vouchedConfigs(configlists)
+	}
+	// replies with the same digest carry the same configurations, so only one of them is merged
+	distinct := make(map[uint32]T, len(replies))
+	digests := make(map[string]bool)
+	for id, r := range replies {
+		if digest := r.GetConfigDigest(); len(digest) > 0 {
+			if digests[string(digest)] {
+				continue
+			}
+			digests[string(digest)] = true
+		}
+		distinct[id] = r
+	}
+	if len(distinct) == 1 {
+		for _, r := range distinct {
+			return joinLattice(replyConfigs(q, r))
 		}
 	}
+	// configurations of the lattice are merged by their changes, since several may have the same timestamp
+	return joinLattice(qf.Merge(distinct, func(r T) []*proto.MetaConfig { return replyConfigs(q, r) }, configKey))
+}
 
-	list := make([]*proto.MetaConfig, 0, len(confs))
-	for _, c := range confs {
-		list = append(list, c)
+// fencedReply is a reply that may be fenced by a server that knows a newer started configuration.
+type fencedReply interface {
+	mconfsReply
+	GetFenced() bool
+}
+
+func notFenced[T fencedReply](r T) bool {
+	return !r.GetFenced()
+}
+
+// supersededBy returns a condition for qf.AbortIf that holds once a fenced reply has been received
+// and the replies vouch for a started configuration that is newer than the configuration with timestamp ts.
+func supersededBy[Req any, Resp fencedReply](q qspec, ts *timestamppb.Timestamp) func(Req, map[uint32]Resp) bool {
+	return func(_ Req, replies map[uint32]Resp) bool {
+		return newerConfig(q, ts, replies, true)
+	}
+}
+
+// stoppedBy is like supersededBy, but holds for any newer configuration, started or not.
+// Servers stop accepting writes to a configuration once they know of a newer one.
+func stoppedBy[Req any](q qspec, ts *timestamppb.Timestamp) func(Req, map[uint32]*proto.WriteResponse) bool {
+	return func(_ Req, replies map[uint32]*proto.WriteResponse) bool {
+		return newerConfig(q, ts, replies, false)
+	}
+}
+
+// newerConfig reports whether a fenced reply has been received and the replies vouch for
+// a configuration that is newer than the configuration with timestamp ts, and started if started is set.
+func newerConfig[Resp fencedReply](q qspec, ts *timestamppb.Timestamp, replies map[uint32]Resp, started bool) bool {
+	if ts == nil || len(qf.Filter(replies, notFenced[Resp])) == len(replies) {
+		return false
+	}
+	for _, c := range combineMConfs(q, replies) {
+		if (c.GetStarted() || !started) && TimeBefore(ts, c.GetTime()) {
+			return true
+		}
 	}
-	return list
+	return false
 }
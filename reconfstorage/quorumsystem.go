@@ -0,0 +1,481 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"reconfstorage/proto"
+)
+
+// quorumSystem decides which sets of nodes form read and write quorums.
+// Every read quorum must intersect every write quorum.
+// Node positions are given by the order of the servers of the configuration.
+type quorumSystem interface {
+	// bind assigns the node IDs, in the order of the servers of the configuration, to the positions of the quorum system.
+	bind(ids []uint32)
+	// isReadQuorum reports whether the nodes in ids contain a read quorum.
+	isReadQuorum(ids map[uint32]bool) bool
+	// isWriteQuorum reports whether the nodes in ids contain a write quorum.
+	isWriteQuorum(ids map[uint32]bool) bool
+	// isMetaQuorum reports whether the nodes in ids intersect every read and every write quorum.
+	// A MetaConfig is stored on such a set of nodes.
+	isMetaQuorum(ids map[uint32]bool) bool
+	// minReadQuorum and minWriteQuorum return the positions of the nodes in a minimal read or write quorum.
+	minReadQuorum() []int
+	minWriteQuorum() []int
+	String() string
+}
+
+// splitConfig splits a configuration string of the form '[nodes]@[quorum system]'.
+// The quorum system is empty if none is given.
+func splitConfig(cfgStr string) (nodes, system string) {
+	if i := strings.Index(cfgStr, "@"); i > -1 {
+		return cfgStr[:i], cfgStr[i+1:]
+	}
+	return cfgStr, ""
+}
+
+// newQuorumSystem returns the quorum system described by spec for n nodes:
+//
+//	majority             majority or weighted voting, using the quorum sizes and weights of conf
+//	grid:[rows]x[cols]   a read quorum is a row, a write quorum is a column
+//	hier:[b1],[b2],...   hierarchical majorities; a tree with branching factor b1 at the root, b2 below, ...
+//	bft:[f]              Byzantine quorums tolerating f faulty servers
+//	ec:[k]               erasure-coded values; any k of the servers' fragments recover a value
+//
+// An empty spec means majority.
+func newQuorumSystem(spec string, n int, conf *proto.MetaConfig) (quorumSystem, error) {
+	name, layout := spec, ""
+	if i := strings.Index(spec, ":"); i > -1 {
+		name, layout = spec[:i], spec[i+1:]
+	}
+	if name != "" && name != "majority" &&
+		(conf.GetReadQuorum() != 0 || conf.GetWriteQuorum() != 0 || len(conf.GetWeights()) != 0) {
+		return nil, fmt.Errorf("quorum sizes and weights are only supported for majority quorums")
+	}
+	switch name {
+	case "", "majority":
+		return newVotingSystem(n, conf.GetReadQuorum(), conf.GetWriteQuorum(), conf.GetWeights())
+	case "grid":
+		var rows, cols int
+		if _, err := fmt.Sscanf(layout, "%dx%d", &rows, &cols); err != nil {
+			return nil, fmt.Errorf("invalid grid layout '%s': %v", layout, err)
+		}
+		return newGridSystem(n, rows, cols)
+	case "hier":
+		var branching []int
+		for _, b := range strings.Split(layout, ",") {
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hierarchical layout '%s': %v", layout, err)
+			}
+			branching = append(branching, v)
+		}
+		return newHierSystem(n, branching)
+	case "bft":
+		f, err := strconv.Atoi(layout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of faults '%s': %v", layout, err)
+		}
+		return newByzantineSystem(n, f)
+	case "ec":
+		k, err := strconv.Atoi(layout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of fragments '%s': %v", layout, err)
+		}
+		return newCodedSystem(n, k)
+	}
+	return nil, fmt.Errorf("unknown quorum system '%s'", name)
+}
+
+// votingSystem uses majorities, or weighted voting with configurable read and write quorum sizes.
+type votingSystem struct {
+	// quorum sizes and the total, counted in votes
+	readQuorum  int
+	writeQuorum int
+	totalVotes  int
+	// weights holds the votes of each position
+	weights []int
+	// byID holds the votes of each node ID
+	byID map[uint32]int
+}
+
+// newVotingSystem returns a voting system for n nodes
+// with read quorums of r votes and write quorums of w votes.
+// weights are the votes of the nodes; if empty, each node has one vote.
+// A quorum size of zero means a majority of the votes.
+// Read and write quorums must intersect, that is r+w must exceed the total votes.
+func newVotingSystem(n int, r, w uint32, weights []uint32) (*votingSystem, error) {
+	q := &votingSystem{readQuorum: int(r), writeQuorum: int(w), weights: make([]int, n)}
+	if len(weights) > 0 && len(weights) != n {
+		return nil, fmt.Errorf("got %d weights for %d servers", len(weights), n)
+	}
+	for i := range q.weights {
+		q.weights[i] = 1
+		if len(weights) > 0 {
+			q.weights[i] = int(weights[i])
+		}
+		q.totalVotes += q.weights[i]
+	}
+	if q.totalVotes == 0 {
+		return nil, fmt.Errorf("servers have no votes")
+	}
+	if q.readQuorum == 0 {
+		q.readQuorum = q.totalVotes/2 + 1
+	}
+	if q.writeQuorum == 0 {
+		q.writeQuorum = q.totalVotes/2 + 1
+	}
+	if q.readQuorum > q.totalVotes || q.writeQuorum > q.totalVotes {
+		return nil, fmt.Errorf("quorum sizes r=%d, w=%d exceed the %d votes", q.readQuorum, q.writeQuorum, q.totalVotes)
+	}
+	if q.readQuorum+q.writeQuorum <= q.totalVotes {
+		return nil, fmt.Errorf("quorums r=%d, w=%d of %d votes do not intersect", q.readQuorum, q.writeQuorum, q.totalVotes)
+	}
+	return q, nil
+}
+
+func (q *votingSystem) bind(ids []uint32) {
+	q.byID = make(map[uint32]int, len(ids))
+	for i, id := range ids {
+		q.byID[id] = q.weights[i]
+	}
+}
+
+// votes returns the total votes of the nodes in ids.
+func (q *votingSystem) votes(ids map[uint32]bool) int {
+	votes := 0
+	for id := range ids {
+		votes += q.byID[id]
+	}
+	return votes
+}
+
+func (q *votingSystem) isReadQuorum(ids map[uint32]bool) bool {
+	return q.votes(ids) >= q.readQuorum
+}
+
+func (q *votingSystem) isWriteQuorum(ids map[uint32]bool) bool {
+	return q.votes(ids) >= q.writeQuorum
+}
+
+// isMetaQuorum requires enough votes to intersect the smaller of the read and write quorums,
+// and at least a majority of the votes.
+func (q *votingSystem) isMetaQuorum(ids map[uint32]bool) bool {
+	min := q.readQuorum
+	if q.writeQuorum < min {
+		min = q.writeQuorum
+	}
+	meta := q.totalVotes - min + 1
+	if meta < q.totalVotes/2+1 {
+		meta = q.totalVotes/2 + 1
+	}
+	return q.votes(ids) >= meta
+}
+
+func (q *votingSystem) minReadQuorum() []int {
+	return q.heaviest(q.readQuorum)
+}
+
+func (q *votingSystem) minWriteQuorum() []int {
+	return q.heaviest(q.writeQuorum)
+}
+
+// heaviest returns the fewest positions whose votes sum to at least quorum.
+func (q *votingSystem) heaviest(quorum int) []int {
+	pos := make([]int, len(q.weights))
+	for i := range pos {
+		pos[i] = i
+	}
+	sort.SliceStable(pos, func(i, j int) bool { return q.weights[pos[i]] > q.weights[pos[j]] })
+	votes := 0
+	for i, p := range pos {
+		if votes += q.weights[p]; votes >= quorum {
+			pos = pos[:i+1]
+			break
+		}
+	}
+	sort.Ints(pos)
+	return pos
+}
+
+func (q *votingSystem) String() string {
+	return fmt.Sprintf("majority (r=%d, w=%d of %d votes)", q.readQuorum, q.writeQuorum, q.totalVotes)
+}
+
+// gridSystem arranges the nodes in a grid, row by row.
+// A read quorum is a complete row and a write quorum is a complete column,
+// so that every read quorum intersects every write quorum.
+type gridSystem struct {
+	rows, cols int
+	ids        []uint32
+}
+
+func newGridSystem(n, rows, cols int) (*gridSystem, error) {
+	if rows < 1 || cols < 1 || rows*cols != n {
+		return nil, fmt.Errorf("grid %dx%d does not fit %d servers", rows, cols, n)
+	}
+	return &gridSystem{rows: rows, cols: cols}, nil
+}
+
+func (q *gridSystem) bind(ids []uint32) {
+	q.ids = ids
+}
+
+func (q *gridSystem) fullRow(ids map[uint32]bool) bool {
+	for r := 0; r < q.rows; r++ {
+		full := true
+		for c := 0; c < q.cols && full; c++ {
+			full = ids[q.ids[r*q.cols+c]]
+		}
+		if full {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *gridSystem) fullColumn(ids map[uint32]bool) bool {
+	for c := 0; c < q.cols; c++ {
+		full := true
+		for r := 0; r < q.rows && full; r++ {
+			full = ids[q.ids[r*q.cols+c]]
+		}
+		if full {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *gridSystem) isReadQuorum(ids map[uint32]bool) bool {
+	return q.fullRow(ids)
+}
+
+func (q *gridSystem) isWriteQuorum(ids map[uint32]bool) bool {
+	return q.fullColumn(ids)
+}
+
+// isMetaQuorum requires a complete row and a complete column,
+// which intersects every column (write quorum) and every row (read quorum).
+func (q *gridSystem) isMetaQuorum(ids map[uint32]bool) bool {
+	return q.fullRow(ids) && q.fullColumn(ids)
+}
+
+func (q *gridSystem) minReadQuorum() []int {
+	pos := make([]int, 0, q.cols)
+	for c := 0; c < q.cols; c++ {
+		pos = append(pos, c)
+	}
+	return pos
+}
+
+func (q *gridSystem) minWriteQuorum() []int {
+	pos := make([]int, 0, q.rows)
+	for r := 0; r < q.rows; r++ {
+		pos = append(pos, r*q.cols)
+	}
+	return pos
+}
+
+func (q *gridSystem) String() string {
+	return fmt.Sprintf("grid %dx%d", q.rows, q.cols)
+}
+
+// hierSystem arranges the nodes as the leaves of a tree.
+// A subtree is available if a majority of its children are available,
+// and a quorum is a set of nodes that makes the root available.
+// Both reads and writes use these quorums, which all intersect.
+type hierSystem struct {
+	branching []int
+	ids       []uint32
+}
+
+func newHierSystem(n int, branching []int) (*hierSystem, error) {
+	size := 1
+	for _, b := range branching {
+		if b < 1 {
+			return nil, fmt.Errorf("invalid branching factor %d", b)
+		}
+		size *= b
+	}
+	if size != n {
+		return nil, fmt.Errorf("hierarchy %v has %d leaves for %d servers", branching, size, n)
+	}
+	return &hierSystem{branching: branching}, nil
+}
+
+func (q *hierSystem) bind(ids []uint32) {
+	q.ids = ids
+}
+
+// available reports whether the subtree at the given level, covering the positions from first, is available.
+func (q *hierSystem) available(ids map[uint32]bool, level, first int) bool {
+	if level == len(q.branching) {
+		return ids[q.ids[first]]
+	}
+	width := q.leaves(level + 1)
+	up := 0
+	for child := 0; child < q.branching[level]; child++ {
+		if q.available(ids, level+1, first+child*width) {
+			up++
+		}
+	}
+	return up > q.branching[level]/2
+}
+
+// leaves returns the number of leaves below a node at the given level.
+func (q *hierSystem) leaves(level int) int {
+	n := 1
+	for _, b := range q.branching[level:] {
+		n *= b
+	}
+	return n
+}
+
+func (q *hierSystem) isReadQuorum(ids map[uint32]bool) bool {
+	return q.available(ids, 0, 0)
+}
+
+func (q *hierSystem) isWriteQuorum(ids map[uint32]bool) bool {
+	return q.available(ids, 0, 0)
+}
+
+func (q *hierSystem) isMetaQuorum(ids map[uint32]bool) bool {
+	return q.available(ids, 0, 0)
+}
+
+// minimal picks the first majority of children at every level.
+func (q *hierSystem) minimal(level, first int) []int {
+	if level == len(q.branching) {
+		return []int{first}
+	}
+	var pos []int
+	width := q.leaves(level + 1)
+	for child := 0; child <= q.branching[level]/2; child++ {
+		pos = append(pos, q.minimal(level+1, first+child*width)...)
+	}
+	return pos
+}
+
+func (q *hierSystem) minReadQuorum() []int {
+	return q.minimal(0, 0)
+}
+
+func (q *hierSystem) minWriteQuorum() []int {
+	return q.minimal(0, 0)
+}
+
+func (q *hierSystem) String() string {
+	b := make([]string, len(q.branching))
+	for i, v := range q.branching {
+		b[i] = strconv.Itoa(v)
+	}
+	return fmt.Sprintf("hierarchical %s", strings.Join(b, "x"))
+}
+
+// thresholdSystem uses every set of at least quorum of its n nodes as a read, write and meta quorum.
+type thresholdSystem struct {
+	n, quorum int
+	ids       map[uint32]bool
+}
+
+func (q *thresholdSystem) bind(ids []uint32) {
+	q.ids = make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		q.ids[id] = true
+	}
+}
+
+func (q *thresholdSystem) isQuorum(ids map[uint32]bool) bool {
+	n := 0
+	for id := range ids {
+		if q.ids[id] {
+			n++
+		}
+	}
+	return n >= q.quorum
+}
+
+func (q *thresholdSystem) isReadQuorum(ids map[uint32]bool) bool  { return q.isQuorum(ids) }
+func (q *thresholdSystem) isWriteQuorum(ids map[uint32]bool) bool { return q.isQuorum(ids) }
+func (q *thresholdSystem) isMetaQuorum(ids map[uint32]bool) bool  { return q.isQuorum(ids) }
+
+func (q *thresholdSystem) minReadQuorum() []int {
+	pos := make([]int, q.quorum)
+	for i := range pos {
+		pos[i] = i
+	}
+	return pos
+}
+
+func (q *thresholdSystem) minWriteQuorum() []int {
+	return q.minReadQuorum()
+}
+
+// byzantineSystem tolerates f servers that reply with arbitrary values.
+// With n ≥ 3f+1 servers, a quorum is any set of ⌊(n+f)/2⌋+1 servers,
+// so that two quorums share at least f+1 servers, at least one of them correct,
+// and a quorum of correct servers is always available.
+type byzantineSystem struct {
+	thresholdSystem
+	f int
+}
+
+func newByzantineSystem(n, f int) (*byzantineSystem, error) {
+	if f < 0 || n < 3*f+1 {
+		return nil, fmt.Errorf("tolerating %d faults needs at least %d servers, got %d", f, 3*f+1, n)
+	}
+	return &byzantineSystem{thresholdSystem{n: n, quorum: (n+f)/2 + 1}, f}, nil
+}
+
+// faults returns the number of faulty servers tolerated.
+func (q *byzantineSystem) faults() int {
+	return q.f
+}
+
+func (q *byzantineSystem) String() string {
+	return fmt.Sprintf("Byzantine (f=%d, quorum %d of %d)", q.f, q.quorum, q.n)
+}
+
+// codedSystem is used for erasure-coded values, where any k of the n fragments recover a value.
+// A quorum is any set of ⌈(n+k)/2⌉ servers, so that two quorums share at least k servers.
+type codedSystem struct {
+	thresholdSystem
+	k int
+}
+
+func newCodedSystem(n, k int) (*codedSystem, error) {
+	if k < 1 || k > n || n > 256 {
+		return nil, fmt.Errorf("cannot recover values from %d of %d fragments", k, n)
+	}
+	return &codedSystem{thresholdSystem{n: n, quorum: (n + k + 1) / 2}, k}, nil
+}
+
+// dataFragments returns the number of fragments needed to recover a value.
+func (q *codedSystem) dataFragments() int {
+	return q.k
+}
+
+func (q *codedSystem) String() string {
+	return fmt.Sprintf("erasure-coded (%d of %d fragments, quorum %d)", q.k, q.n, q.quorum)
+}
+
+// faultTolerance returns the number of Byzantine servers the quorum system tolerates.
+func faultTolerance(system quorumSystem) int {
+	if b, ok := system.(interface{ faults() int }); ok {
+		return b.faults()
+	}
+	return 0
+}
+
+// dataFragments returns the number of fragments needed to recover an erasure-coded value,
+// or 0 if the quorum system stores full copies.
+func dataFragments(system quorumSystem) int {
+	if c, ok := system.(interface{ dataFragments() int }); ok {
+		return c.dataFragments()
+	}
+	return 0
+}
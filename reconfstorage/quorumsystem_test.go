@@ -0,0 +1,188 @@
+package main
+
+import (
+	"testing"
+
+	"reconfstorage/proto"
+)
+
+// nodeSet returns the set of the node IDs ids.
+func nodeSet(ids ...uint32) map[uint32]bool {
+	set := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// bindTest binds system to the node IDs 1 to n and returns it.
+func bindTest(system quorumSystem, n int) quorumSystem {
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = uint32(i + 1)
+	}
+	system.bind(ids)
+	return system
+}
+
+// quorumCase is a set of nodes and whether it is a read, write and meta quorum.
+type quorumCase struct {
+	name              string
+	ids               map[uint32]bool
+	read, write, meta bool
+}
+
+func checkQuorums(t *testing.T, system quorumSystem, tests []quorumCase) {
+	t.Helper()
+	for _, test := range tests {
+		if got := system.isReadQuorum(test.ids); got != test.read {
+			t.Errorf("%s %s: isReadQuorum = %v, want %v", system, test.name, got, test.read)
+		}
+		if got := system.isWriteQuorum(test.ids); got != test.write {
+			t.Errorf("%s %s: isWriteQuorum = %v, want %v", system, test.name, got, test.write)
+		}
+		if got := system.isMetaQuorum(test.ids); got != test.meta {
+			t.Errorf("%s %s: isMetaQuorum = %v, want %v", system, test.name, got, test.meta)
+		}
+	}
+}
+
+func TestNewVotingSystem(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		r, w uint32
+		ok   bool
+	}{
+		{"majority", 5, 0, 0, true},
+		{"read one", 5, 1, 5, true},
+		{"write one", 5, 5, 1, true},
+		{"read three write three", 5, 3, 3, true},
+		{"no intersection", 5, 2, 3, false},
+		{"read too large", 5, 6, 0, false},
+		{"write too large", 5, 0, 6, false},
+	}
+	for _, test := range tests {
+		_, err := newVotingSystem(test.n, test.r, test.w, nil)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: newVotingSystem(%d, %d, %d) = %v, want ok %v", test.name, test.n, test.r, test.w, err, test.ok)
+		}
+	}
+}
+
+func TestVotingQuorumSizes(t *testing.T) {
+	majority, err := newVotingSystem(5, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(majority, 5), []quorumCase{
+		{"none", nodeSet(), false, false, false},
+		{"two", nodeSet(1, 2), false, false, false},
+		{"three", nodeSet(1, 2, 5), true, true, true},
+		{"unknown nodes", nodeSet(6, 7, 8), false, false, false},
+	})
+
+	readOne, err := newVotingSystem(5, 1, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(readOne, 5), []quorumCase{
+		{"one", nodeSet(3), true, false, false},
+		{"four", nodeSet(1, 2, 3, 4), true, false, false},
+		{"all", nodeSet(1, 2, 3, 4, 5), true, true, true},
+	})
+}
+
+func TestLevelQuorumSizes(t *testing.T) {
+	q := newTestQSpec(t, 5)
+	tests := []struct {
+		level proto.Consistency
+		size  int
+	}{
+		{proto.Consistency_ONE, 1},
+		{proto.Consistency_MAJORITY, 3},
+		{proto.Consistency_ALL, 5},
+	}
+	all := []uint32{1, 2, 3, 4, 5}
+	for _, test := range tests {
+		isQuorum := q.levelQuorum(test.level, q.system.isWriteQuorum)
+		if isQuorum(nodeSet(all[:test.size-1]...)) {
+			t.Errorf("%v: %d nodes are a quorum", test.level, test.size-1)
+		}
+		if !isQuorum(nodeSet(all[:test.size]...)) {
+			t.Errorf("%v: %d nodes are not a quorum", test.level, test.size)
+		}
+	}
+}
+
+func TestNewQuorumSystemSizes(t *testing.T) {
+	conf := &proto.MetaConfig{ReadQuorum: 2, WriteQuorum: 4}
+	if _, err := newQuorumSystem("", 5, conf); err != nil {
+		t.Errorf("majority with r=2, w=4: %v", err)
+	}
+	if _, err := newQuorumSystem("grid:1x5", 5, conf); err == nil {
+		t.Error("grid with quorum sizes: got no error")
+	}
+}
+
+func TestWeightedVoting(t *testing.T) {
+	// nodes 1 and 2 have two votes each, nodes 3 to 5 one vote: 7 votes, majority 4
+	weighted, err := newVotingSystem(5, 0, 0, []uint32{2, 2, 1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(weighted, 5), []quorumCase{
+		{"two heavy", nodeSet(1, 2), true, true, true},
+		{"three light", nodeSet(3, 4, 5), false, false, false},
+		{"heavy and light", nodeSet(1, 3, 4), true, true, true},
+		{"heavy and one light", nodeSet(2, 5), false, false, false},
+	})
+	if got := weighted.minReadQuorum(); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("minReadQuorum = %v, want [0 1]", got)
+	}
+
+	if _, err := newVotingSystem(3, 0, 0, []uint32{1, 1}); err == nil {
+		t.Error("two weights for three servers: got no error")
+	}
+	if _, err := newVotingSystem(2, 0, 0, []uint32{0, 0}); err == nil {
+		t.Error("servers without votes: got no error")
+	}
+}
+
+func TestGridSystem(t *testing.T) {
+	// 1 2 3
+	// 4 5 6
+	grid, err := newGridSystem(6, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(grid, 6), []quorumCase{
+		{"row", nodeSet(4, 5, 6), true, false, false},
+		{"column", nodeSet(2, 5), false, true, false},
+		{"row and column", nodeSet(1, 2, 3, 6), true, true, true},
+		{"diagonal", nodeSet(1, 5), false, false, false},
+	})
+	if _, err := newGridSystem(6, 2, 2); err == nil {
+		t.Error("grid 2x2 for 6 servers: got no error")
+	}
+}
+
+func TestHierSystem(t *testing.T) {
+	// three groups of three: 1 2 3 | 4 5 6 | 7 8 9
+	hier, err := newHierSystem(9, []int{3, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQuorums(t, bindTest(hier, 9), []quorumCase{
+		{"two groups", nodeSet(1, 2, 4, 5), true, true, true},
+		{"one group", nodeSet(1, 2, 3), false, false, false},
+		{"majority of nodes in one group", nodeSet(1, 2, 3, 4, 7), false, false, false},
+		{"minimal", nodeSet(1, 2, 4, 6), true, true, true},
+	})
+	if got := hier.minReadQuorum(); len(got) != 4 {
+		t.Errorf("minReadQuorum = %v, want 4 positions", got)
+	}
+	if _, err := newHierSystem(8, []int{3, 3}); err == nil {
+		t.Error("hierarchy 3x3 for 8 servers: got no error")
+	}
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"reconfstorage/proto"
@@ -28,13 +30,37 @@ nodes                           Print a list of the available nodes
 rpc    [node index] [operation]	Executes an RPC on the given node.
 qc     [operation]             	Executes a quorum call on all nodes.
 mcast  [key] [value]            Executes a multicast write call on all nodes.
-cfg    [config]              	Updates the default configuration.
-reconf [config]              	Reconfigure to new configuration.
+cfg    [config]              	Updates the default configuration. Configurations may list new servers by address.
+reconf [config] [options]    	Reconfigure to new configuration. Options:
+                             	  -r [votes] -w [votes]  read and write quorum sizes
+                             	  -weights [w0,w1,...]   vote weight of each node
+session [new|resume [token]]	Print, restart or resume the session used by qc read and write.
+level  [one|majority|all]    	Print or set the default consistency level of quorum calls.
+quorums [config]             	Print the nodes of a minimal read and write quorum of the current or given configuration.
+thrifty [on|off]             	Print or set whether reads and writes contact the fastest quorum first.
+hedge  [on|off]              	Print or set whether reads and writes are resent to slow nodes.
+mode   [direct|paxos|lattice|joint]	Print or set how reconf moves to the next configuration:
+                             	  direct   announce the goal as the next configuration
+                             	  paxos    let the current servers choose the next configuration with Paxos
+                             	  lattice  merge concurrent changes with lattice agreement
+                             	  joint    pass through a joint configuration of the old and new servers
+stats                        	Print the number of quorum calls and messages, and the latency of each node.
 
 The following operations are supported:
 
 read 	[key]        	Read a value
 write	[key] [value]	Write a value
+list 	             	List all keys (qc and rpc only)
+aread	[key]...     	Read several values concurrently (qc only)
+awrite	[key] [value]...	Write several values concurrently (qc only)
+cread	[key]        	Read a value and print each refinement (qc only)
+
+aread and awrite are not part of the session: they give no read-your-writes
+or monotonic reads, and their values are not recorded in the session token.
+
+Quorum calls use the default consistency level. Append '-level [level]'
+to a qc read, write or list to override it for a single command;
+aread, awrite and cread always use the default level.
 
 Examples:
 
@@ -49,16 +75,42 @@ Updates to configuration with nodes 1 and 2
 
 > cfg 0,2
 Updates to configuration with nodes 0 and 2
+
+> reconf 1,2,10.0.0.5:8080
+Reconfigures to nodes 1 and 2 and the server at 10.0.0.5:8080, which is dialed if it is not known yet
+
+> reconf 0:4 -r 1 -w 4
+Reconfigures to nodes 0 to 3, reading from any one node and writing to all four
+
+> reconf 0:4 -weights 3,1,1,1
+Keeps nodes 0 to 3, but node 0 has three votes; a quorum needs four of the six votes
+
+> reconf 0:9@grid:3x3
+Reconfigures to nodes 0 to 8 in a 3x3 grid; reads use a row and writes use a column
+
+> reconf 0:9@hier:3,3
+Reconfigures to nodes 0 to 8 in three groups of three; a quorum is a majority of each of a majority of the groups
+
+> reconf 0:4@bft:1
+Reconfigures to nodes 0 to 3, tolerating one node that replies with arbitrary values
+
+> reconf 0:5@ec:3
+Reconfigures to nodes 0 to 4, storing erasure-coded fragments; any three recover a value
+
+> qc read foo -level one
+The command reads 'foo' from the first replica that replies
 `
 
 type repl struct {
 	*client
-	term *term.Terminal
+	session *session
+	term    *term.Terminal
 }
 
 func newRepl(c *client) *repl {
 	return &repl{
-		client: c,
+		client:  c,
+		session: newSession(),
 		term: term.NewTerminal(struct {
 			io.Reader
 			io.Writer
@@ -66,6 +118,14 @@ func newRepl(c *client) *repl {
 	}
 }
 
+// prompt returns the prompt of the terminal, which shows whether the current configuration is joint.
+func (r repl) prompt() string {
+	if isJoint(r.current()) {
+		return "[joint] > "
+	}
+	return "> "
+}
+
 // ReadLine reads a line from the terminal in raw mode.
 //
 // FIXME: ReadLine currently does not work with arrow keys on windows for some reason
@@ -93,6 +153,7 @@ func Repl(c *client) {
 
 	fmt.Println(help)
 	for {
+		r.term.SetPrompt(r.prompt())
 		l, err := r.ReadLine()
 		if errors.Is(err, io.EOF) {
 			return
@@ -125,6 +186,20 @@ func Repl(c *client) {
 			r.cfgc(args[1:])
 		case "reconf":
 			r.reconf(args[1:])
+		case "session":
+			r.sessionc(args[1:])
+		case "level":
+			r.levelc(args[1:])
+		case "quorums":
+			r.quorums(args[1:])
+		case "thrifty":
+			r.thriftyc(args[1:])
+		case "hedge":
+			r.hedgec(args[1:])
+		case "mode":
+			r.modec(args[1:])
+		case "stats":
+			r.statsc()
 		case "mcast":
 			fallthrough
 		case "multicast":
@@ -174,10 +249,54 @@ func (r repl) reconf(args []string) {
 		fmt.Println("'reconf' requires a configuration.")
 		return
 	}
-	r.client.reconf(args[0])
+	goal, err := parseMetaConfig(args[0], args[1:])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if r.reconfMode() == reconfJoint {
+		// run the two steps of joint consensus separately to show the joint phase
+		joint, err := r.enterJoint(goal)
+		if err != nil {
+			fmt.Printf("Reconfiguration failed: %v\n", err)
+			return
+		}
+		fmt.Printf("Joint phase: quorums of both '%s' and '%s'\n", joint.GetOld().GetAdds(), joint.GetAdds())
+		if err := r.leaveJoint(); err != nil {
+			fmt.Printf("Reconfiguration failed in the joint phase: %v\n", err)
+			return
+		}
+	} else if err := r.client.reconf(goal); err != nil {
+		fmt.Printf("Reconfiguration failed: %v\n", err)
+		return
+	}
 	fmt.Println("Reconfiguration finished")
 }
 
+// parseMetaConfig returns a MetaConfig for the configuration string cfgStr
+// with the options '-r [votes]', '-w [votes]' and '-weights [w0,w1,...]' given in args.
+func parseMetaConfig(cfgStr string, args []string) (*proto.MetaConfig, error) {
+	fs := flag.NewFlagSet("reconf", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	rq := fs.Uint("r", 0, "read quorum")
+	wq := fs.Uint("w", 0, "write quorum")
+	weights := fs.String("weights", "", "vote weights")
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("invalid options: %v", err)
+	}
+	goal := &proto.MetaConfig{Adds: cfgStr, ReadQuorum: uint32(*rq), WriteQuorum: uint32(*wq)}
+	if *weights != "" {
+		for _, w := range strings.Split(*weights, ",") {
+			v, err := strconv.ParseUint(w, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight '%s'", w)
+			}
+			goal.Weights = append(goal.Weights, uint32(v))
+		}
+	}
+	return goal, nil
+}
+
 func (r repl) readRPC(args []string, node *proto.Node) {
 	if len(args) < 1 {
 		fmt.Println("Read requires a key to read.")
@@ -203,7 +322,9 @@ func (r repl) writeRPC(args []string, node *proto.Node) {
 		return
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	resp, err := node.WriteRPC(ctx, &proto.WriteRequest{Key: args[0], Value: args[1], Time: timestamppb.Now()})
+	req := &proto.WriteRequest{Key: args[0], Value: args[1], Time: timestamppb.Now()}
+	r.keys.signWrite(req)
+	resp, err := node.WriteRPC(ctx, req)
 	cancel()
 	if err != nil {
 		fmt.Printf("Write RPC finished with error: %v\n", err)
@@ -250,22 +371,46 @@ func (r repl) qc(args []string) {
 		return
 	}
 
+	rest, level, ok := r.levelOption(args)
+	if !ok {
+		return
+	}
+	if len(rest) < 1 {
+		fmt.Println("'qc' requires an operation.")
+		return
+	}
+	if len(rest) < len(args) && (rest[0] == "aread" || rest[0] == "awrite" || rest[0] == "cread") {
+		fmt.Printf("'qc %s' uses the default consistency level; set it with 'level'.\n", rest[0])
+		return
+	}
+	args = rest
+
 	switch args[0] {
 	case "read":
-		r.doReadQC(args[1:])
+		r.doReadQC(args[1:], level)
 	case "write":
-		r.doWriteQC(args[1:])
+		r.doWriteQC(args[1:], level)
 	case "list":
-		r.doListQC()
+		r.doListQC(level)
+	case "aread":
+		r.doReadAsync(args[1:])
+	case "awrite":
+		r.doWriteAsync(args[1:])
+	case "cread":
+		r.doReadCorrectable(args[1:])
 	}
 }
 
-func (r repl) doReadQC(args []string) {
+func (r repl) doReadQC(args []string, level proto.Consistency) {
 	if len(args) < 1 {
 		fmt.Println("Read requires a key to read.")
 		return
 	}
-	resp := r.read(args[0])
+	resp, err := r.sessionRead(r.session, args[0], level)
+	if err != nil {
+		fmt.Printf("Read failed: %v\n", err)
+		return
+	}
 	if !resp.GetOK() {
 		fmt.Printf("%s was not found\n", args[0])
 		return
@@ -273,22 +418,95 @@ func (r repl) doReadQC(args []string) {
 	fmt.Printf("%s = %s\n", args[0], resp.GetValue())
 }
 
-func (r repl) doWriteQC(args []string) {
+func (r repl) doWriteQC(args []string, level proto.Consistency) {
 	if len(args) < 2 {
 		fmt.Println("Write requires a key and a value to write.")
 		return
 	}
-	resp := r.write(args[0], args[1])
-	if !resp.GetNew() {
+	_, err := r.sessionWrite(r.session, args[0], args[1], level)
+	if errors.Is(err, ErrStaleTimestamp) {
 		fmt.Printf("Failed to update %s: timestamp too old.\n", args[0])
 		return
 	}
+	if err != nil {
+		fmt.Printf("Write failed: %v\n", err)
+		return
+	}
 	fmt.Println("Write OK")
 }
 
-func (r repl) doListQC() {
+func (r repl) doReadAsync(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Read requires at least one key to read.")
+		return
+	}
+	futures := make([]*readFuture, len(args))
+	for i, key := range args {
+		futures[i] = r.readAsync(key)
+	}
+	for i, f := range futures {
+		resp, err := f.Get()
+		if err != nil {
+			fmt.Printf("Read %s failed: %v\n", args[i], err)
+			continue
+		}
+		if !resp.GetOK() {
+			fmt.Printf("%s was not found\n", args[i])
+			continue
+		}
+		fmt.Printf("%s = %s\n", args[i], resp.GetValue())
+	}
+}
 
-	resp := r.list()
+func (r repl) doWriteAsync(args []string) {
+	if len(args) < 2 || len(args)%2 != 0 {
+		fmt.Println("Write requires pairs of keys and values to write.")
+		return
+	}
+	futures := make([]*writeFuture, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		futures = append(futures, r.writeAsync(args[i], args[i+1]))
+	}
+	for i, f := range futures {
+		key := args[2*i]
+		_, err := f.Get()
+		if errors.Is(err, ErrStaleTimestamp) {
+			fmt.Printf("Failed to update %s: timestamp too old.\n", key)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Write %s failed: %v\n", key, err)
+			continue
+		}
+		fmt.Printf("Write %s OK\n", key)
+	}
+}
+
+func (r repl) doReadCorrectable(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Read requires a key to read.")
+		return
+	}
+	rc := r.readCorrectable(args[0], LevelAll)
+	for ref := range rc.Watch() {
+		if !ref.Resp.GetOK() {
+			fmt.Printf("[%s, %d nodes] %s was not found\n", levelName(ref.Level), ref.Replies, args[0])
+			continue
+		}
+		fmt.Printf("[%s, %d nodes] %s = %s\n", levelName(ref.Level), ref.Replies, args[0], ref.Resp.GetValue())
+	}
+	if _, _, err := rc.Get(); err != nil {
+		fmt.Printf("Read failed: %v\n", err)
+	}
+}
+
+func (r repl) doListQC(level proto.Consistency) {
+
+	resp, err := r.list(level)
+	if err != nil {
+		fmt.Printf("List failed: %v\n", err)
+		return
+	}
 
 	if len(resp.GetKeys()) == 0 {
 		fmt.Println("No keys found.")
@@ -307,10 +525,173 @@ func (r repl) cfgc(args []string) {
 		fmt.Println("'cfg' requires a configuration.")
 		return
 	}
-	cfg, err := r.parseConfiguration(args[0])
+	cfg, err := r.parseConfiguration(&proto.MetaConfig{Adds: args[0]})
 	if err != nil {
+		fmt.Println(err)
 		return
 	}
 
+	r.mu.Lock()
 	r.cfg = cfg
+	r.mu.Unlock()
+}
+
+func (r repl) quorums(args []string) {
+	conf := r.current()
+	if len(args) > 0 {
+		conf = &proto.MetaConfig{Adds: args[0]}
+	}
+	system, nodes, err := r.parseQuorumSystem(conf)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	index := make(map[string]string)
+	for i, n := range r.mgr.Nodes() {
+		index[n.Address()] = strconv.Itoa(i)
+	}
+	show := func(name string, pos []int) {
+		fmt.Printf("Minimal %s quorum (%d nodes):\n", name, len(pos))
+		for _, p := range pos {
+			// servers given by address are not known to the client until they are dialed
+			i, ok := index[nodes[p]]
+			if !ok {
+				i = "unknown"
+			}
+			fmt.Printf("%s: %s\n", i, nodes[p])
+		}
+	}
+	fmt.Printf("Configuration '%s' uses %s quorums\n", conf.GetAdds(), system)
+	show("read", system.minReadQuorum())
+	show("write", system.minWriteQuorum())
+}
+
+func (r repl) thriftyc(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "on":
+			r.setThrifty(true)
+		case "off":
+			r.setThrifty(false)
+		default:
+			fmt.Printf("Unknown option '%s'. Use 'on' or 'off'.\n", args[0])
+			return
+		}
+	}
+	fmt.Printf("Thrifty quorum calls: %t\n", r.isThrifty())
+}
+
+func (r repl) hedgec(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "on":
+			r.setHedged(true)
+		case "off":
+			r.setHedged(false)
+		default:
+			fmt.Printf("Unknown option '%s'. Use 'on' or 'off'.\n", args[0])
+			return
+		}
+	}
+	fmt.Printf("Hedged quorum calls: %t\n", r.isHedged())
+}
+
+func (r repl) modec(args []string) {
+	if len(args) > 0 {
+		mode := -1
+		for m := reconfDirect; m <= reconfJoint; m++ {
+			if args[0] == m.String() {
+				mode = int(m)
+			}
+		}
+		if mode < 0 {
+			fmt.Printf("Unknown mode '%s'. Use 'direct', 'paxos', 'lattice' or 'joint'.\n", args[0])
+			return
+		}
+		r.setReconfMode(reconfMode(mode))
+	}
+	fmt.Printf("Reconfiguration mode: %s\n", r.reconfMode())
+}
+
+func (r repl) statsc() {
+	stats := r.stats.snapshot()
+	fmt.Printf("%d quorum calls sent %d messages\n", stats.Calls, stats.Messages)
+	fmt.Printf("%d calls were widened, %d calls were hedged\n", stats.Widened, stats.Hedged)
+	fmt.Printf("Hedging delay: %v\n", r.callTimes.percentile(hedgePercentile))
+	for i, n := range r.mgr.Nodes() {
+		if lat, ok := r.latencies.estimate(n.ID()); ok {
+			fmt.Printf("%d: %s %v\n", i, n.Address(), lat)
+		}
+	}
+}
+
+func (r *repl) sessionc(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "new":
+			r.session = newSession()
+			fmt.Println("Started new session")
+			return
+		case "resume":
+			if len(args) < 2 {
+				fmt.Println("'session resume' requires a token.")
+				return
+			}
+			s, err := resumeSession(args[1])
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			r.session = s
+			fmt.Println("Resumed session")
+			return
+		default:
+			fmt.Printf("Unknown session command '%s'.\n", args[0])
+			return
+		}
+	}
+	token, err := r.session.Token()
+	if err != nil {
+		fmt.Printf("Failed to encode session: %v\n", err)
+		return
+	}
+	fmt.Println("Session token:", token)
+}
+
+func (r repl) levelc(args []string) {
+	if len(args) < 1 {
+		fmt.Printf("Consistency level: %s\n", r.consistency())
+		return
+	}
+	level, err := parseLevel(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	r.setConsistency(level)
+	fmt.Printf("Consistency level: %s\n", level)
+}
+
+// levelOption removes a trailing '-level [level]' from args.
+// It returns the remaining arguments and the level to use, which is the client's default if none was given.
+func (r repl) levelOption(args []string) ([]string, proto.Consistency, bool) {
+	n := len(args)
+	if n < 2 || args[n-2] != "-level" {
+		return args, r.consistency(), true
+	}
+	level, err := parseLevel(args[n-1])
+	if err != nil {
+		fmt.Println(err)
+		return nil, 0, false
+	}
+	return args[:n-2], level, true
+}
+
+// parseLevel parses one of the consistency levels one, majority or all.
+func parseLevel(s string) (proto.Consistency, error) {
+	v, ok := proto.Consistency_value[strings.ToUpper(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown consistency level '%s': use one, majority or all", s)
+	}
+	return proto.Consistency(v), nil
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"reconfstorage/proto"
+)
+
+// captureOutput returns what f prints to standard output.
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	f()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestLevelOption(t *testing.T) {
+	r := repl{client: &client{level: proto.Consistency_MAJORITY}}
+	tests := []struct {
+		args  []string
+		rest  int
+		level proto.Consistency
+		ok    bool
+	}{
+		{[]string{"read", "k"}, 2, proto.Consistency_MAJORITY, true},
+		{[]string{"read", "k", "-level", "one"}, 2, proto.Consistency_ONE, true},
+		{[]string{"write", "k", "v", "-level", "ALL"}, 3, proto.Consistency_ALL, true},
+		{[]string{"-level", "one"}, 0, proto.Consistency_ONE, true},
+		{[]string{"read", "k", "-level", "some"}, 0, 0, false},
+		// '-level' is only an option at the end
+		{[]string{"write", "-level", "one", "v"}, 4, proto.Consistency_MAJORITY, true},
+	}
+	for _, test := range tests {
+		var (
+			rest  []string
+			level proto.Consistency
+			ok    bool
+		)
+		captureOutput(t, func() { rest, level, ok = r.levelOption(test.args) })
+		if len(rest) != test.rest || level != test.level || ok != test.ok {
+			t.Errorf("levelOption(%v) = %v, %v, %v; want %d arguments, %v, %v", test.args, rest, level, ok, test.rest, test.level, test.ok)
+		}
+	}
+}
+
+func TestQCArguments(t *testing.T) {
+	// the client is not connected: each command must be rejected before it is run
+	r := repl{client: &client{level: proto.Consistency_MAJORITY}}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "requires an operation"},
+		{[]string{"-level", "one"}, "requires an operation"},
+		{[]string{"read", "k", "-level", "some"}, "unknown consistency level"},
+		{[]string{"aread", "k", "-level", "one"}, "uses the default consistency level"},
+		{[]string{"awrite", "k", "v", "-level", "all"}, "uses the default consistency level"},
+		{[]string{"cread", "k", "-level", "majority"}, "uses the default consistency level"},
+	}
+	for _, test := range tests {
+		if out := captureOutput(t, func() { r.qc(test.args) }); !strings.Contains(out, test.want) {
+			t.Errorf("qc %v printed %q, want %q", test.args, out, test.want)
+		}
+	}
+}
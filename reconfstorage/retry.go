@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// retryPolicy decides how often and how fast a failed quorum call is repeated.
+// The backoff grows exponentially from InitialBackoff up to MaxBackoff,
+// and each delay is randomized by up to Jitter (a fraction of the delay).
+type retryPolicy struct {
+	// MaxAttempts limits the number of attempts, including the first one. Zero means no limit.
+	MaxAttempts int
+	// MaxElapsed limits the total time spent on all attempts. Zero means no limit.
+	MaxElapsed     time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	// Retryable reports whether an attempt that failed with err should be repeated.
+	Retryable func(err error) bool
+	// now and sleep replace time.Now and time.Sleep if set, so that tests can use a fake clock.
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts:    5,
+	MaxElapsed:     10 * time.Second,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	Retryable:      retryable,
+}
+
+// noRetry performs a single attempt.
+var noRetry = retryPolicy{MaxAttempts: 1}
+
+// retryable retries quorum calls that did not reach a quorum.
+// Invalid configurations, stale timestamps and superseded configurations
+// will not change by trying again.
+func retryable(err error) bool {
+	switch {
+	case errors.Is(err, ErrInvalidConfig),
+		errors.Is(err, ErrStaleTimestamp),
+		errors.Is(err, ErrConfigSuperseded):
+		return false
+	case errors.Is(err, ErrQuorumNotReached):
+		return true
+	}
+	return false
+}
+
+// do calls op until it succeeds, returns an error that should not be retried,
+// or the policy runs out of attempts or time.
+// op must be idempotent, e.g. writes have to reuse the same timestamp.
+func (p retryPolicy) do(op func() error) error {
+	now, sleep := p.now, p.sleep
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	start := now()
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil {
+			return nil
+		}
+		if !p.shouldRetry(err) {
+			return err
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		delay := p.jitter(backoff)
+		if elapsed := now().Sub(start); p.MaxElapsed > 0 && elapsed+delay > p.MaxElapsed {
+			return fmt.Errorf("giving up after %d attempts (%v): %w", attempt, elapsed.Round(time.Millisecond), err)
+		}
+		sleep(delay)
+		backoff = p.next(backoff)
+	}
+}
+
+// shouldRetry reports whether the policy retries err.
+func (p retryPolicy) shouldRetry(err error) bool {
+	return p.Retryable != nil && p.Retryable(err)
+}
+
+// next returns the backoff following d.
+func (p retryPolicy) next(d time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		d = time.Duration(float64(d) * p.Multiplier)
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// jitter randomizes d by up to ±p.Jitter·d.
+func (p retryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 || d <= 0 {
+		return d
+	}
+	delta := p.Jitter * float64(d)
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
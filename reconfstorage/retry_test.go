@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClock records the delays of a retry policy instead of sleeping.
+type fakeClock struct {
+	t      time.Time
+	delays []time.Duration
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func (f *fakeClock) sleep(d time.Duration) {
+	f.delays = append(f.delays, d)
+	f.t = f.t.Add(d)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	failing := fmt.Errorf("ReadQC: %w", ErrQuorumNotReached)
+	tests := []struct {
+		name     string
+		policy   retryPolicy
+		failures int
+		err      error
+		attempts int
+		delays   []time.Duration
+		ok       bool
+	}{
+		{
+			name:     "succeeds after backoff",
+			policy:   retryPolicy{MaxAttempts: 5, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Retryable: retryable},
+			failures: 3, err: failing,
+			attempts: 4,
+			delays:   []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond},
+			ok:       true,
+		},
+		{
+			name:     "backoff is capped",
+			policy:   retryPolicy{MaxAttempts: 5, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond, Multiplier: 2, Retryable: retryable},
+			failures: 10, err: failing,
+			attempts: 5,
+			delays:   []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond, 25 * time.Millisecond},
+		},
+		{
+			name:     "gives up when the time is up",
+			policy:   retryPolicy{MaxElapsed: 100 * time.Millisecond, InitialBackoff: 40 * time.Millisecond, Multiplier: 1, Retryable: retryable},
+			failures: 10, err: failing,
+			attempts: 3,
+			delays:   []time.Duration{40 * time.Millisecond, 40 * time.Millisecond},
+		},
+		{
+			name:     "does not retry a superseded configuration",
+			policy:   retryPolicy{MaxAttempts: 5, InitialBackoff: 10 * time.Millisecond, Retryable: retryable},
+			failures: 10, err: fmt.Errorf("write config: %w", ErrConfigSuperseded),
+			attempts: 1,
+		},
+		{
+			name:     "single attempt",
+			policy:   noRetry,
+			failures: 10, err: failing,
+			attempts: 1,
+		},
+	}
+	for _, test := range tests {
+		clock := &fakeClock{t: time.Unix(0, 0)}
+		p := test.policy
+		p.now, p.sleep = clock.now, clock.sleep
+		attempts := 0
+		err := p.do(func() error {
+			attempts++
+			if attempts <= test.failures {
+				return test.err
+			}
+			return nil
+		})
+		if (err == nil) != test.ok {
+			t.Errorf("%s: err = %v, want ok %v", test.name, err, test.ok)
+		}
+		if err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: err = %v, does not wrap %v", test.name, err, test.err)
+		}
+		if attempts != test.attempts {
+			t.Errorf("%s: %d attempts, want %d", test.name, attempts, test.attempts)
+		}
+		if fmt.Sprint(clock.delays) != fmt.Sprint(test.delays) {
+			t.Errorf("%s: delays %v, want %v", test.name, clock.delays, test.delays)
+		}
+	}
+}
+
+func TestRetryJitterBounds(t *testing.T) {
+	p := retryPolicy{Jitter: 0.2}
+	d := 100 * time.Millisecond
+	for i := 0; i < 1000; i++ {
+		if j := p.jitter(d); j < 80*time.Millisecond || j > 120*time.Millisecond {
+			t.Fatalf("jitter(%v) = %v, want within 20%%", d, j)
+		}
+	}
+	if j := (retryPolicy{}).jitter(d); j != d {
+		t.Errorf("jitter without Jitter = %v, want %v", j, d)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"log"
 	"net"
@@ -16,7 +17,8 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func startServer(address string) (*gorums.Server, string) {
+// startServer starts a storage server on address, which trusts the tombstones signed by the trusted writers, see Bury.
+func startServer(address string, trusted ...ed25519.PublicKey) (*gorums.Server, string) {
 	// listen on given address
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
@@ -26,6 +28,9 @@ func startServer(address string) (*gorums.Server, string) {
 	// init server implementation
 	storage := newStorageServer()
 	storage.logger = log.New(os.Stderr, fmt.Sprintf("%s: ", lis.Addr()), log.Ltime|log.Lmicroseconds|log.Lmsgprefix)
+	if len(trusted) > 0 {
+		storage.keys = trustedKeys(trusted...)
+	}
 
 	// create Gorums server
 	srv := gorums.NewServer()
@@ -42,12 +47,12 @@ func startServer(address string) (*gorums.Server, string) {
 	return srv, lis.Addr().String()
 }
 
-func runServer(address string) {
+func runServer(address string, trusted ...ed25519.PublicKey) {
 	// catch signals in order to shut down gracefully
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	srv, addr := startServer(address)
+	srv, addr := startServer(address, trusted...)
 
 	log.Printf("Started storage server on %s\n", addr)
 
@@ -59,20 +64,59 @@ func runServer(address string) {
 type state struct {
 	Value string
 	Time  time.Time
+	// signature and public key of the writer
+	Signature []byte
+	Writer    []byte
+}
+
+// version is a fragment of an erasure-coded value.
+// Fragments are only returned by reads once they are finalized.
+type version struct {
+	Time      time.Time
+	Fragment  *proto.Fragment
+	Finalized bool
 }
 
 // storageServer is an implementation of proto.Storage
 type storageServer struct {
 	storage map[string]state
-	configs []*proto.MetaConfig
-	mut     sync.RWMutex
-	logger  *log.Logger
+	// fragments holds the versions of erasure-coded values:
+	// the newest finalized version, and any newer versions that are not finalized yet
+	fragments map[string][]version
+	configs   []*proto.MetaConfig
+	// digest is the configListDigest of configs
+	digest []byte
+	// acceptors holds the Paxos state of each slot, keyed by the timestamp of the configuration
+	acceptors map[time.Time]*acceptor
+	// lattices holds the changes accepted in lattice agreement on the successor of each configuration, keyed by configKey
+	lattices map[string][]*proto.Change
+	// latticeQuorums holds the quorums of the first proposal for the successor of each configuration, keyed by configKey
+	latticeQuorums map[string]*proto.MetaConfig
+	// decommissioned is set once the server has received a tombstone and dropped its data, see decommission.go;
+	// self is the server as named in the tombstone
+	decommissioned bool
+	self           *proto.Server
+	// keys holds the writers whose tombstones the server trusts; without keys, tombstones are not verified
+	keys *keyring
+	// jobs holds the progress of the reconfigurations to configurations of the server, by their timestamp
+	jobs map[time.Time]*proto.Progress
+	// peers parses the old configurations that the server pulls the state from, see transfer.go
+	peers  *client
+	mut    sync.RWMutex
+	logger *log.Logger
 }
 
 func newStorageServer() *storageServer {
 	return &storageServer{
-		storage: make(map[string]state),
-		configs: make([]*proto.MetaConfig, 0, 1),
+		storage:        make(map[string]state),
+		fragments:      make(map[string][]version),
+		configs:        make([]*proto.MetaConfig, 0, 1),
+		digest:         configListDigest(nil),
+		acceptors:      make(map[time.Time]*acceptor),
+		lattices:       make(map[string][]*proto.Change),
+		latticeQuorums: make(map[string]*proto.MetaConfig),
+		jobs:           make(map[time.Time]*proto.Progress),
+		peers:          newPeerClient(),
 	}
 }
 
@@ -96,6 +140,16 @@ func (s *storageServer) WriteQC(_ gorums.ServerCtx, req *proto.WriteRequest) (re
 	return s.Write(req)
 }
 
+// ReadQCAsync is an RPC handler for an asynchronous quorum call
+func (s *storageServer) ReadQCAsync(_ gorums.ServerCtx, req *proto.ReadRequest) (resp *proto.ReadResponse, err error) {
+	return s.Read(req)
+}
+
+// WriteQCAsync is an RPC handler for an asynchronous quorum call
+func (s *storageServer) WriteQCAsync(_ gorums.ServerCtx, req *proto.WriteRequest) (resp *proto.WriteResponse, err error) {
+	return s.Write(req)
+}
+
 func (s *storageServer) ListKeysRPC(_ gorums.ServerCtx, req *proto.ListRequest) (*proto.ListResponse, error) {
 	return s.ListKeys(req)
 }
@@ -108,6 +162,41 @@ func (s *storageServer) WriteMetaConfQC(_ gorums.ServerCtx, req *proto.MetaConfi
 	return s.WriteConfig(req)
 }
 
+// PreWriteQC is an RPC handler for a quorum call
+func (s *storageServer) PreWriteQC(_ gorums.ServerCtx, req *proto.WriteRequest) (resp *proto.WriteResponse, err error) {
+	return s.PreWrite(req)
+}
+
+// FinalizeQC is an RPC handler for a quorum call
+func (s *storageServer) FinalizeQC(_ gorums.ServerCtx, req *proto.WriteRequest) (resp *proto.WriteResponse, err error) {
+	return s.Finalize(req)
+}
+
+// ReadFragmentQC is an RPC handler for a quorum call
+func (s *storageServer) ReadFragmentQC(_ gorums.ServerCtx, req *proto.ReadRequest) (resp *proto.ReadResponse, err error) {
+	return s.ReadFragment(req)
+}
+
+// PrepareQC is an RPC handler for a quorum call
+func (s *storageServer) PrepareQC(_ gorums.ServerCtx, req *proto.PrepareRequest) (resp *proto.PromiseResponse, err error) {
+	return s.Prepare(req)
+}
+
+// AcceptQC is an RPC handler for a quorum call
+func (s *storageServer) AcceptQC(_ gorums.ServerCtx, req *proto.AcceptRequest) (resp *proto.AcceptResponse, err error) {
+	return s.Accept(req)
+}
+
+// ProposeQC is an RPC handler for a quorum call
+func (s *storageServer) ProposeQC(_ gorums.ServerCtx, req *proto.LatticeRequest) (resp *proto.LatticeResponse, err error) {
+	return s.Propose(req)
+}
+
+// ProgressQC is an RPC handler for a quorum call
+func (s *storageServer) ProgressQC(_ gorums.ServerCtx, req *proto.Progress) (resp *proto.Progress, err error) {
+	return s.Progress(req)
+}
+
 func (s *storageServer) WriteMulticast(_ gorums.ServerCtx, req *proto.WriteRequest) {
 	_, err := s.Write(req)
 	if err != nil {
@@ -120,11 +209,15 @@ func (s *storageServer) Read(req *proto.ReadRequest) (*proto.ReadResponse, error
 	s.logger.Printf("Read '%s'\n", req.GetKey())
 	s.mut.RLock()
 	defer s.mut.RUnlock()
+	if s.decommissioned || s.fenced(req.GetConfigTime()) {
+		return &proto.ReadResponse{Fenced: true, MConfigs: s.configs}, nil
+	}
+	mconfs, known := s.configsFor(req.GetConfigDigest(), req.GetConfigTime())
 	state, ok := s.storage[req.GetKey()]
 	if !ok {
-		return &proto.ReadResponse{OK: false, MConfigs: s.configs}, nil
+		return &proto.ReadResponse{OK: false, MConfigs: mconfs, ConfigDigest: known}, nil
 	}
-	return &proto.ReadResponse{OK: true, Value: state.Value, Time: timestamppb.New(state.Time), MConfigs: s.configs}, nil
+	return &proto.ReadResponse{OK: true, Value: state.Value, Time: timestamppb.New(state.Time), MConfigs: mconfs, ConfigDigest: known, Signature: state.Signature, Writer: state.Writer}, nil
 }
 
 // Write writes a new value to storage if it is newer than the old value
@@ -132,12 +225,151 @@ func (s *storageServer) Write(req *proto.WriteRequest) (*proto.WriteResponse, er
 	s.logger.Printf("Write '%s' = '%s'\n", req.GetKey(), req.GetValue())
 	s.mut.Lock()
 	defer s.mut.Unlock()
+	if s.decommissioned || s.stopped(req.GetConfigTime()) {
+		return &proto.WriteResponse{Fenced: true, MConfigs: s.configs}, nil
+	}
+	mconfs, known := s.configsFor(req.GetConfigDigest(), req.GetConfigTime())
 	oldState, ok := s.storage[req.GetKey()]
 	if ok && oldState.Time.After(req.GetTime().AsTime()) {
-		return &proto.WriteResponse{New: false, MConfigs: s.configs}, nil
+		return &proto.WriteResponse{New: false, MConfigs: mconfs, ConfigDigest: known}, nil
 	}
-	s.storage[req.GetKey()] = state{Value: req.GetValue(), Time: req.GetTime().AsTime()}
-	return &proto.WriteResponse{New: true, MConfigs: s.configs}, nil
+	s.storage[req.GetKey()] = state{Value: req.GetValue(), Time: req.GetTime().AsTime(), Signature: req.GetSignature(), Writer: req.GetWriter()}
+	return &proto.WriteResponse{New: true, MConfigs: mconfs, ConfigDigest: known}, nil
+}
+
+// PreWrite stores the fragment of an erasure-coded value, if it is newer than the finalized value
+func (s *storageServer) PreWrite(req *proto.WriteRequest) (*proto.WriteResponse, error) {
+	s.logger.Printf("Pre-write '%s' fragment %d\n", req.GetKey(), req.GetFragment().GetIndex())
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.decommissioned || s.stopped(req.GetConfigTime()) {
+		return &proto.WriteResponse{Fenced: true, MConfigs: s.configs}, nil
+	}
+	mconfs, known := s.configsFor(req.GetConfigDigest(), req.GetConfigTime())
+	ts := req.GetTime().AsTime()
+	if final, ok := s.finalized(req.GetKey()); ok && final.Time.After(ts) {
+		return &proto.WriteResponse{New: false, MConfigs: mconfs, ConfigDigest: known}, nil
+	}
+	versions := s.fragments[req.GetKey()]
+	for i, v := range versions {
+		if v.Time.Equal(ts) {
+			versions[i].Fragment = req.GetFragment()
+			return &proto.WriteResponse{New: true, MConfigs: mconfs, ConfigDigest: known}, nil
+		}
+	}
+	s.fragments[req.GetKey()] = append(versions, version{Time: ts, Fragment: req.GetFragment()})
+	return &proto.WriteResponse{New: true, MConfigs: mconfs, ConfigDigest: known}, nil
+}
+
+// Finalize marks the erasure-coded value with the requested time as finalized
+func (s *storageServer) Finalize(req *proto.WriteRequest) (*proto.WriteResponse, error) {
+	s.logger.Printf("Finalize '%s'\n", req.GetKey())
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.decommissioned || s.stopped(req.GetConfigTime()) {
+		return &proto.WriteResponse{Fenced: true, MConfigs: s.configs}, nil
+	}
+	mconfs, known := s.configsFor(req.GetConfigDigest(), req.GetConfigTime())
+	s.finalize(req.GetKey(), req.GetTime().AsTime())
+	return &proto.WriteResponse{New: true, MConfigs: mconfs, ConfigDigest: known}, nil
+}
+
+// ReadFragment returns the time of the newest finalized erasure-coded value,
+// or, if a time is requested, finalizes that value and returns its fragment.
+func (s *storageServer) ReadFragment(req *proto.ReadRequest) (*proto.ReadResponse, error) {
+	s.logger.Printf("Read fragment '%s'\n", req.GetKey())
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.decommissioned || s.fenced(req.GetConfigTime()) {
+		return &proto.ReadResponse{Fenced: true, MConfigs: s.configs}, nil
+	}
+	mconfs, known := s.configsFor(req.GetConfigDigest(), req.GetConfigTime())
+	if req.GetTime() == nil {
+		final, ok := s.finalized(req.GetKey())
+		if !ok {
+			return &proto.ReadResponse{OK: false, MConfigs: mconfs, ConfigDigest: known}, nil
+		}
+		return &proto.ReadResponse{OK: true, Time: timestamppb.New(final.Time), MConfigs: mconfs, ConfigDigest: known}, nil
+	}
+	ts := req.GetTime().AsTime()
+	s.finalize(req.GetKey(), ts)
+	for _, v := range s.fragments[req.GetKey()] {
+		if v.Time.Equal(ts) && v.Fragment != nil {
+			return &proto.ReadResponse{OK: true, Time: req.GetTime(), Fragment: v.Fragment, MConfigs: mconfs, ConfigDigest: known}, nil
+		}
+	}
+	return &proto.ReadResponse{OK: false, Time: req.GetTime(), MConfigs: mconfs, ConfigDigest: known}, nil
+}
+
+// finalized returns the newest finalized version of key.
+func (s *storageServer) finalized(key string) (version, bool) {
+	var final version
+	found := false
+	for _, v := range s.fragments[key] {
+		if v.Finalized && (!found || v.Time.After(final.Time)) {
+			final, found = v, true
+		}
+	}
+	return final, found
+}
+
+// finalize marks the version of key with time ts as finalized,
+// and removes all older versions. s.mut must be held.
+func (s *storageServer) finalize(key string, ts time.Time) {
+	if final, ok := s.finalized(key); ok && final.Time.After(ts) {
+		return
+	}
+	versions := make([]version, 0, len(s.fragments[key])+1)
+	known := false
+	for _, v := range s.fragments[key] {
+		if v.Time.Before(ts) {
+			continue
+		}
+		if v.Time.Equal(ts) {
+			v.Finalized, known = true, true
+		}
+		versions = append(versions, v)
+	}
+	if !known {
+		// finalized without the fragment; reads of this version are served by other servers
+		versions = append(versions, version{Time: ts, Finalized: true})
+	}
+	s.fragments[key] = versions
+}
+
+// fenced reports whether the server knows a started configuration that is newer than
+// the configuration with timestamp ts, to which a request was sent.
+// Requests without a configuration timestamp are never fenced.
+// The caller must hold s.mut.
+func (s *storageServer) fenced(ts *timestamppb.Timestamp) bool {
+	if ts == nil {
+		return false
+	}
+	for _, c := range s.configs {
+		if c.GetStarted() && ts.AsTime().Before(c.GetTime().AsTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// stopped reports whether the server knows any configuration that is newer than
+// the configuration with timestamp ts. A newer configuration acts as a stop sign:
+// writes to the old configuration are fenced as soon as the new one is announced,
+// so that the state read by the state transfer does not change.
+// Only writes that need a write quorum are guaranteed to meet a stopped server;
+// a write at level ONE can still be acknowledged by a server that has not received the stop sign.
+// The caller must hold s.mut.
+func (s *storageServer) stopped(ts *timestamppb.Timestamp) bool {
+	if ts == nil {
+		return false
+	}
+	for _, c := range s.configs {
+		if ts.AsTime().Before(c.GetTime().AsTime()) {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *storageServer) WriteConfig(req *proto.MetaConfig) (*proto.WriteResponse, error) {
@@ -145,10 +377,48 @@ func (s *storageServer) WriteConfig(req *proto.MetaConfig) (*proto.WriteResponse
 	s.mut.Lock()
 	defer s.mut.Unlock()
 
-	// TODO:
-	// store new MetaConfig in the servers list,
-	// consider if it is started
-	// consider if it is older than a started configuration
+	// ignore configurations that are older than a started configuration
+	for _, c := range s.configs {
+		if c.GetStarted() && req.GetTime().AsTime().Before(c.GetTime().AsTime()) {
+			return &proto.WriteResponse{New: false, MConfigs: s.configs}, nil
+		}
+	}
+
+	configs := make([]*proto.MetaConfig, 0, len(s.configs)+1)
+	known := false
+	for _, c := range s.configs {
+		// a started configuration replaces all earlier configurations
+		if req.GetStarted() && c.GetTime().AsTime().Before(req.GetTime().AsTime()) {
+			continue
+		}
+		if configKey(c) == configKey(req) {
+			known = true
+			if req.GetStarted() {
+				c = req
+			}
+		}
+		configs = append(configs, c)
+	}
+	if !known {
+		configs = append(configs, req)
+	}
+	s.configs = configs
+	s.digest = configListDigest(configs)
+	s.revive(req)
+	if req.GetStarted() {
+		// the reconfigurations to older configurations are finished or abandoned,
+		// and the successors of older configurations are chosen
+		for slot := range s.jobs {
+			if slot.Before(req.GetTime().AsTime()) {
+				delete(s.jobs, slot)
+			}
+		}
+		for slot := range s.acceptors {
+			if slot.Before(req.GetTime().AsTime()) {
+				delete(s.acceptors, slot)
+			}
+		}
+	}
 
 	return &proto.WriteResponse{New: true, MConfigs: s.configs}, nil
 }
@@ -158,11 +428,20 @@ func (s *storageServer) ListKeys(req *proto.ListRequest) (*proto.ListResponse, e
 	s.logger.Printf("List request")
 	s.mut.Lock()
 	defer s.mut.Unlock()
+	if s.decommissioned || s.fenced(req.GetConfigTime()) {
+		return &proto.ListResponse{Fenced: true, MConfigs: s.configs}, nil
+	}
+	mconfs, known := s.configsFor(req.GetConfigDigest(), req.GetConfigTime())
 	keys := make([]string, 0, len(s.storage))
 
 	for k := range s.storage {
 		keys = append(keys, k)
 	}
+	for k := range s.fragments {
+		if _, ok := s.storage[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
 
-	return &proto.ListResponse{Keys: keys, MConfigs: s.configs}, nil
+	return &proto.ListResponse{Keys: keys, MConfigs: mconfs, ConfigDigest: known}, nil
 }
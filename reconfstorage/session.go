@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"reconfstorage/proto"
+
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// session provides read-your-writes and monotonic reads for a sequence of operations.
+// It remembers the newest timestamp written and read for each key,
+// and the newest configuration seen.
+type session struct {
+	mu    sync.Mutex
+	token *proto.SessionToken
+}
+
+func newSession() *session {
+	return &session{token: &proto.SessionToken{
+		Written: make(map[string]*timestamppb.Timestamp),
+		Read:    make(map[string]*timestamppb.Timestamp),
+	}}
+}
+
+// resumeSession continues the session encoded in token, as returned by session.Token.
+func resumeSession(token string) (*session, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session token: %w", err)
+	}
+	s := newSession()
+	if err := pb.Unmarshal(b, s.token); err != nil {
+		return nil, fmt.Errorf("invalid session token: %w", err)
+	}
+	if s.token.Written == nil {
+		s.token.Written = make(map[string]*timestamppb.Timestamp)
+	}
+	if s.token.Read == nil {
+		s.token.Read = make(map[string]*timestamppb.Timestamp)
+	}
+	return s, nil
+}
+
+// Token encodes the session, so that it can be handed to another process.
+func (s *session) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := pb.Marshal(s.token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// minTime returns the oldest timestamp a read of key may return in this session.
+func (s *session) minTime(key string) *timestamppb.Timestamp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	min := s.token.Written[key]
+	if r := s.token.Read[key]; min == nil || TimeBefore(min, r) {
+		min = r
+	}
+	return min
+}
+
+func (s *session) observeRead(key string, ts *timestamppb.Timestamp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur := s.token.Read[key]; ts != nil && (cur == nil || TimeBefore(cur, ts)) {
+		s.token.Read[key] = ts
+	}
+}
+
+func (s *session) observeWrite(key string, ts *timestamppb.Timestamp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur := s.token.Written[key]; cur == nil || TimeBefore(cur, ts) {
+		s.token.Written[key] = ts
+	}
+}
+
+func (s *session) observeConfig(conf *proto.MetaConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if conf.GetStarted() && (s.token.Config == nil || TimeBefore(s.token.Config.GetTime(), conf.GetTime())) {
+		s.token.Config = conf
+	}
+}
+
+func (s *session) config() *proto.MetaConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token.Config
+}
+
+// sessionRead reads key and makes sure the value is not older than
+// anything written or read before in the session.
+// If the first read returns an older value, the read is repeated on all replicas
+// of the configuration and its successors, until the policy's attempts are used up.
+func (c *client) sessionRead(s *session, key string, level proto.Consistency) (resp *proto.ReadResponse, err error) {
+	start := c.sessionStart(s)
+	min := s.minTime(key)
+
+	policy := c.retry
+	policy.Retryable = func(err error) bool { return errors.Is(err, ErrSessionViolated) }
+	first := true
+	err = policy.do(func() error {
+		var err error
+		if first {
+			resp, err = c.readFrom(key, level, start, nil)
+			first = false
+		} else {
+			resp, err = c.readAll(key, start)
+		}
+		if err != nil {
+			return err
+		}
+		if TimeBefore(resp.GetTime(), min) {
+			return fmt.Errorf("read %q: %w", key, ErrSessionViolated)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.observeRead(key, resp.GetTime())
+	s.observeConfig(c.current())
+	return resp, nil
+}
+
+// sessionWrite writes key and records the write in the session.
+func (c *client) sessionWrite(s *session, key, value string, level proto.Consistency) (*proto.WriteResponse, error) {
+	ts := timestamppb.Now()
+	resp, err := c.writeFrom(key, value, ts, level, c.sessionStart(s), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.observeWrite(key, ts)
+	s.observeConfig(c.current())
+	return resp, nil
+}
+
+// sessionStart returns the configuration an operation of the session starts from:
+// the client's current configuration, or the session's if it is newer.
+// The session's configuration comes from the token and is not installed on the client;
+// the client only moves on to configurations that it learns from the servers.
+func (c *client) sessionStart(s *session) *proto.MetaConfig {
+	start := c.current()
+	if conf := s.config(); conf.GetStarted() && TimeBefore(start.GetTime(), conf.GetTime()) {
+		return conf
+	}
+	return start
+}
+
+// readAll reads key from all replicas of the configuration start that reply within the timeout,
+// and from its successors.
+func (c *client) readAll(key string, start *proto.MetaConfig) (*proto.ReadResponse, error) {
+	rc := c.readCorrectableFrom(key, LevelAll, start)
+	<-rc.Done()
+	resp, _, err := rc.Get()
+	return resp, err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSessionTokenAdvances(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	s := newSession()
+	if _, err := c.sessionWrite(s, "k", "v1", proto.Consistency_MAJORITY); err != nil {
+		t.Fatal(err)
+	}
+	first := s.minTime("k")
+	if first == nil {
+		t.Fatal("the write was not recorded in the session")
+	}
+	if _, err := c.sessionWrite(s, "k", "v2", proto.Consistency_MAJORITY); err != nil {
+		t.Fatal(err)
+	}
+	if second := s.minTime("k"); !TimeBefore(first, second) {
+		t.Errorf("token = %v after the second write, want newer than %v", second, first)
+	}
+
+	// a session resumed from the token reads its own writes
+	token, err := s.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := resumeSession(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.sessionRead(resumed, "k", proto.Consistency_MAJORITY)
+	if err != nil || resp.GetValue() != "v2" {
+		t.Errorf("read = %v, %v; want v2", resp.GetValue(), err)
+	}
+}
+
+func TestSessionReadBelowToken(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	c.retry = retryPolicy{MaxAttempts: 2}
+	if _, err := c.write("k", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	// the session has seen a write of k that no replica stores
+	s := newSession()
+	s.observeWrite("k", timestamppb.New(time.Now().Add(time.Hour)))
+	if _, err := c.sessionRead(s, "k", proto.Consistency_MAJORITY); !errors.Is(err, ErrSessionViolated) {
+		t.Errorf("read below the session token: err = %v, want %v", err, ErrSessionViolated)
+	}
+}
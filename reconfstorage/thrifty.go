@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"reconfstorage/proto"
+
+	"github.com/relab/gorums"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// initialTimeout is the widening timeout of a thrifty call before any latencies are known.
+	initialTimeout = 50 * time.Millisecond
+	// minTimeout is the lower bound of the widening timeout.
+	minTimeout = 2 * time.Millisecond
+)
+
+// latencies keeps a smoothed estimate of the reply latency of each node,
+// in the same way as TCP estimates the round-trip time (RFC 6298).
+type latencies struct {
+	mu   sync.Mutex
+	srtt map[uint32]time.Duration
+	dev  map[uint32]time.Duration
+}
+
+func newLatencies() *latencies {
+	return &latencies{srtt: make(map[uint32]time.Duration), dev: make(map[uint32]time.Duration)}
+}
+
+// record adds a latency sample of the node id.
+func (l *latencies) record(id uint32, sample time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	srtt, ok := l.srtt[id]
+	if !ok {
+		l.srtt[id] = sample
+		l.dev[id] = sample / 2
+		return
+	}
+	diff := srtt - sample
+	if diff < 0 {
+		diff = -diff
+	}
+	l.dev[id] = (3*l.dev[id] + diff) / 4
+	l.srtt[id] = (7*srtt + sample) / 8
+}
+
+// estimate returns the smoothed latency of the node id, and whether it is known.
+func (l *latencies) estimate(id uint32) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	srtt, ok := l.srtt[id]
+	return srtt, ok
+}
+
+// fastest orders ids by their estimated latency. Nodes without an estimate come first, so that they are measured.
+func (l *latencies) fastest(ids []uint32) []uint32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sorted := append([]uint32(nil), ids...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, aok := l.srtt[sorted[i]]
+		b, bok := l.srtt[sorted[j]]
+		if aok != bok {
+			return !aok
+		}
+		return a < b
+	})
+	return sorted
+}
+
+// timeout returns how long to wait for the nodes in ids before widening a call:
+// the largest smoothed latency plus four times its deviation.
+func (l *latencies) timeout(ids []uint32) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var timeout time.Duration
+	for _, id := range ids {
+		srtt, ok := l.srtt[id]
+		if !ok {
+			return initialTimeout
+		}
+		if t := srtt + 4*l.dev[id]; t > timeout {
+			timeout = t
+		}
+	}
+	if timeout < minTimeout {
+		return minTimeout
+	}
+	return timeout
+}
+
+// callCounts are the counters of callStats.
+type callCounts struct {
+	Calls    int
+	Messages int
+	// Widened counts the thrifty calls that were sent to the remaining nodes
+	Widened int
+	// Hedged counts the calls that were sent again to the nodes that had not replied
+	Hedged int
+}
+
+// callStats counts the quorum calls of a client and the messages they sent.
+type callStats struct {
+	mu sync.Mutex
+	callCounts
+}
+
+func (s *callStats) add(messages int, widened bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Calls++
+	s.Messages += messages
+	if widened {
+		s.Widened++
+	}
+}
+
+// hedge records that a call was sent again in messages.
+func (s *callStats) hedge(messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Messages += messages
+	s.Hedged++
+}
+
+// snapshot returns a copy of the counters.
+func (s *callStats) snapshot() callCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.callCounts
+}
+
+// callState holds the replies of an ongoing call that is sent to the nodes in several quorum calls,
+// such as a thrifty or hedged call, so that each quorum call sees the replies of the others.
+type callState struct {
+	mu    sync.Mutex
+	start time.Time
+	// sent holds when the request was sent to the nodes that were not sent it at start
+	sent    map[uint32]time.Time
+	lat     *latencies
+	replies map[uint32]any
+}
+
+// pendingCalls maps the request of an ongoing thrifty or hedged call to its callState.
+var pendingCalls sync.Map
+
+func newCallState(lat *latencies) *callState {
+	return &callState{start: time.Now(), sent: make(map[uint32]time.Time), lat: lat, replies: make(map[uint32]any)}
+}
+
+// startCall registers a new callState for the call of req, so that mergedReplies
+// combines the replies of its quorum calls. The returned function ends the call.
+func startCall(req any, lat *latencies) (*callState, func()) {
+	state := newCallState(lat)
+	pendingCalls.Store(req, state)
+	return state, func() { pendingCalls.Delete(req) }
+}
+
+// sendTo records that the request is now sent to the nodes ids,
+// so that their latency is measured from now rather than from the start of the call.
+func (s *callState) sendTo(ids []uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, id := range ids {
+		s.sent[id] = now
+	}
+}
+
+// mergedReplies returns replies together with the replies received by the other quorum calls of the call of in,
+// and records the latency of new replies, measured from when the request was sent to the node.
+// Quorum functions call it before inspecting replies.
+// Only the first reply of each node is kept; duplicate replies are ignored.
+func mergedReplies[T any](in any, replies map[uint32]T) map[uint32]T {
+	v, ok := pendingCalls.Load(in)
+	if !ok {
+		return replies
+	}
+	s := v.(*callState)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, r := range replies {
+		if _, ok := s.replies[id]; !ok {
+			s.replies[id] = r
+			sent, ok := s.sent[id]
+			if !ok {
+				sent = s.start
+			}
+			s.lat.record(id, time.Since(sent))
+		}
+	}
+	merged := make(map[uint32]T, len(s.replies))
+	for id, r := range s.replies {
+		merged[id] = r.(T)
+	}
+	return merged
+}
+
+// replied reports whether the node id has replied in the call.
+func (s *callState) replied(id uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.replies[id]
+	return ok
+}
+
+// thriftyCall runs a quorum call first on the fastest nodes of conf that form a quorum.
+// If these do not complete the call within the adaptive timeout,
+// the call is widened to the remaining nodes, and the replies of both phases are combined.
+// Nodes that did not reply in time are charged the timeout as their latency,
+// and the latency of the remaining nodes is measured from when the call is widened.
+func thriftyCall[Req any, Resp any](c *client, conf *proto.MetaConfig, isQuorum func(quorumSystem) func(map[uint32]bool) bool,
+	req Req, call func(context.Context, *proto.Configuration, Req) (Resp, error)) (resp Resp, err error) {
+	cfg, qs, err := c.newConfiguration(conf)
+	if err != nil {
+		return resp, err
+	}
+
+	ids := make([]uint32, 0, cfg.Size())
+	for _, n := range cfg.Nodes() {
+		ids = append(ids, n.ID())
+	}
+	// the fastest nodes that form a quorum
+	fast := make(map[uint32]bool)
+	var first, rest []uint32
+	for _, id := range c.latencies.fastest(ids) {
+		if isQuorum(qs.system)(fast) {
+			rest = append(rest, id)
+			continue
+		}
+		fast[id] = true
+		first = append(first, id)
+	}
+
+	state, end := startCall(req, c.latencies)
+	defer end()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if len(rest) == 0 {
+		c.stats.add(len(first), false)
+		return call(ctx, cfg, req)
+	}
+
+	timeout := c.latencies.timeout(first)
+	sub, err := c.createConfiguration(qs, gorums.WithNodeIDs(first))
+	if err != nil {
+		return resp, err
+	}
+	phase, cancelPhase := context.WithTimeout(ctx, timeout)
+	resp, err = call(phase, sub, req)
+	cancelPhase()
+	if err == nil {
+		c.stats.add(len(first), false)
+		return resp, nil
+	}
+	var qcErr gorums.QuorumCallError
+	if !errors.As(err, &qcErr) {
+		c.stats.add(len(first), false)
+		return resp, err
+	}
+	for _, id := range first {
+		if !state.replied(id) {
+			c.latencies.record(id, timeout)
+		}
+	}
+
+	// widen to the remaining nodes
+	c.stats.add(len(first)+len(rest), true)
+	wide, err := c.createConfiguration(qs, gorums.WithNodeIDs(rest))
+	if err != nil {
+		return resp, err
+	}
+	state.sendTo(rest)
+	return call(ctx, wide, req)
+}
+
+// isThrifty reports whether the client uses thrifty quorum calls.
+func (c *client) isThrifty() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.thrifty
+}
+
+func (c *client) setThrifty(thrifty bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.thrifty = thrifty
+}
+
+// readThrifty reads key from the configuration conf with a thrifty quorum call.
+func (c *client) readThrifty(key string, level proto.Consistency, conf *proto.MetaConfig) (resp *proto.ReadResponse, err error) {
+	isQuorum := func(s quorumSystem) func(map[uint32]bool) bool { return levelQuorum(level, s.isReadQuorum) }
+	err = c.retry.do(func() error {
+		req := &proto.ReadRequest{Key: key, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()}
+		resp, err = thriftyCall(c, conf, isQuorum, req, func(ctx context.Context, cfg *proto.Configuration, req *proto.ReadRequest) (*proto.ReadResponse, error) {
+			return cfg.ReadQC(ctx, req)
+		})
+		return quorumError("ReadQC", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeThrifty writes the value with timestamp ts to the configuration conf with a thrifty quorum call.
+func (c *client) writeThrifty(key, value string, ts *timestamppb.Timestamp, level proto.Consistency, conf *proto.MetaConfig) (resp *proto.WriteResponse, err error) {
+	isQuorum := func(s quorumSystem) func(map[uint32]bool) bool { return levelQuorum(level, s.isWriteQuorum) }
+	req := &proto.WriteRequest{Key: key, Value: value, Time: ts, Consistency: level, ConfigDigest: c.known.digest(), ConfigTime: conf.GetTime()}
+	c.keys.signWrite(req)
+	err = c.retry.do(func() error {
+		resp, err = thriftyCall(c, conf, isQuorum, req, func(ctx context.Context, cfg *proto.Configuration, req *proto.WriteRequest) (*proto.WriteResponse, error) {
+			return cfg.WriteQC(ctx, req)
+		})
+		return quorumError("WriteQC", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// levelQuorum returns the sets of nodes that satisfy the consistency level.
+// ALL is never satisfied, so that thrifty calls contact all nodes.
+func levelQuorum(level proto.Consistency, isQuorum func(map[uint32]bool) bool) func(map[uint32]bool) bool {
+	switch level {
+	case proto.Consistency_ONE:
+		return func(ids map[uint32]bool) bool { return len(ids) >= 1 }
+	case proto.Consistency_ALL:
+		return func(map[uint32]bool) bool { return false }
+	}
+	return isQuorum
+}
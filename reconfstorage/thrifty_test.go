@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+)
+
+// TestThriftyDemotesDeadNode replays widened thrifty calls in which node 1 of the first phase
+// has crashed and node 2 replies promptly once the call is widened to it.
+func TestThriftyDemotesDeadNode(t *testing.T) {
+	lat := newLatencies()
+	// before the crash node 1 was the faster one
+	lat.record(1, time.Millisecond)
+	lat.record(2, 2*time.Millisecond)
+	if got := lat.fastest([]uint32{1, 2}); got[0] != 1 {
+		t.Fatalf("fastest = %v, want node 1 first", got)
+	}
+
+	for call := 1; call <= 5; call++ {
+		req := &proto.ReadRequest{Key: "k"}
+		state, end := startCall(req, lat)
+
+		// the first phase times out without a reply from node 1
+		timeout := lat.timeout([]uint32{1})
+		time.Sleep(timeout)
+		lat.record(1, timeout)
+
+		// node 2 replies shortly after the call is widened to it
+		state.sendTo([]uint32{2})
+		mergedReplies(req, map[uint32]*proto.ReadResponse{2: {OK: true}})
+		end()
+
+		if got := lat.fastest([]uint32{1, 2}); got[0] == 2 {
+			t.Logf("node 1 demoted after %d calls", call)
+			return
+		}
+	}
+	est1, _ := lat.estimate(1)
+	est2, _ := lat.estimate(2)
+	t.Errorf("dead node 1 (%v) is still estimated faster than node 2 (%v)", est1, est2)
+}
+
+func TestLatencyTimeout(t *testing.T) {
+	lat := newLatencies()
+	if got := lat.timeout([]uint32{1}); got != initialTimeout {
+		t.Errorf("timeout of an unknown node = %v, want %v", got, initialTimeout)
+	}
+	lat.record(1, 10*time.Millisecond)
+	// srtt 10ms with deviation 5ms
+	if got, want := lat.timeout([]uint32{1}), 30*time.Millisecond; got != want {
+		t.Errorf("timeout = %v, want %v", got, want)
+	}
+	lat.record(2, 0)
+	if got := lat.timeout([]uint32{2}); got != minTimeout {
+		t.Errorf("timeout of an instant node = %v, want %v", got, minTimeout)
+	}
+}
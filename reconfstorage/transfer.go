@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"reconfstorage/proto"
+	"reconfstorage/qf"
+
+	"github.com/relab/gorums"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// transferTimeout bounds the time the new servers take to pull the state.
+	transferTimeout = time.Minute
+	// transferBatchSize is the most values an old server sends per TransferStateQC call.
+	transferBatchSize = 100
+	// transferChunkSize is the most values a new server pulls from each old server per PullStateQC call.
+	// The client records the progress between calls, so that a takeover can skip the keys pulled so far.
+	transferChunkSize = 1000
+)
+
+// transferState copies the state from the old configurations sources to the new configuration goal,
+// starting after the keys the job has already transferred.
+// The servers of goal pull the state from the old servers themselves, see pullState.
+// Configurations that servers cannot pull from each other, see streamable, are copied by the client
+// one key at a time, see copyState; the client logs why.
+func (c *client) transferState(job *job, sources []*proto.MetaConfig, goal *proto.MetaConfig, goalCfg *proto.Configuration) error {
+	if !job.progress.GetTransferred() {
+		var err error
+		if reason := c.streamable(append([]*proto.MetaConfig{goal}, sources...)); reason == nil {
+			err = c.pullState(job, sources, goal, goalCfg)
+		} else {
+			log.Printf("Copying the state to '%s' through the client: %v\n", goal.GetAdds(), reason)
+			err = c.copyState(job, goal, goalCfg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return job.finish()
+}
+
+// streamable returns nil if servers can pull the state of the configurations confs from each other:
+// their servers are given by address and ID, and they store full copies without tolerating Byzantine servers.
+// Otherwise it returns the reason why not: servers of Byzantine configurations cannot check the signatures
+// of the values, and servers of erasure-coded configurations cannot decode fragments.
+func (c *client) streamable(confs []*proto.MetaConfig) error {
+	for _, conf := range confs {
+		if len(conf.GetServers()) == 0 {
+			return fmt.Errorf("the servers of '%s' are not given by address and ID", conf.GetAdds())
+		}
+		system, _, err := c.parseQuorumSystem(conf)
+		switch {
+		case err != nil:
+			return err
+		case faultTolerance(system) > 0:
+			return fmt.Errorf("servers cannot verify the values of the Byzantine configuration '%s'", conf.GetAdds())
+		case dataFragments(system) > 0:
+			return fmt.Errorf("servers cannot decode the fragments of the erasure-coded configuration '%s'", conf.GetAdds())
+		}
+	}
+	return nil
+}
+
+// pullState asks the servers of goal to pull the state from read quorums of sources,
+// and waits until a write quorum of goal has done so.
+// The servers pull the keys after the job's cursor in chunks, and the cursor is advanced after each chunk.
+func (c *client) pullState(job *job, sources []*proto.MetaConfig, goal *proto.MetaConfig, goalCfg *proto.Configuration) error {
+	for {
+		after := job.progress.GetCursor()
+		resp, err := c.pullChunk(job, &proto.PullRequest{Sources: sources, After: after, Config: goal.GetTime(), Limit: transferChunkSize}, goalCfg)
+		if err != nil {
+			return err
+		}
+		if resp.GetFenced() {
+			// a newer configuration was announced and stopped the goal configuration
+			return ErrConfigSuperseded
+		}
+		if resp.GetDone() {
+			return nil
+		}
+		if resp.GetCursor() <= after {
+			return fmt.Errorf("state transfer made no progress after '%s'", after)
+		}
+		if err := job.transferred(resp.GetCursor()); err != nil {
+			return err
+		}
+	}
+}
+
+// pullChunk sends req to the servers of goal, and waits until a write quorum of them has pulled the chunk.
+// The progress is reported while waiting, so that other clients do not take over the installation.
+func (c *client) pullChunk(job *job, req *proto.PullRequest, goalCfg *proto.Configuration) (*proto.PullResponse, error) {
+	var resp *proto.PullResponse
+	done := make(chan error, 1)
+	go func() {
+		done <- c.retry.do(func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+			defer cancel()
+			var err error
+			resp, err = goalCfg.PullStateQC(ctx, req)
+			return quorumError("PullStateQC", err)
+		})
+	}()
+	ticker := time.NewTicker(c.jobTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		case <-ticker.C:
+			if err := job.report(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// copyState reads each key from the old configurations and writes it to goal, in the order of the keys.
+// State transfer always uses majorities, independent of the client's default level.
+func (c *client) copyState(job *job, goal *proto.MetaConfig, goalCfg *proto.Configuration) error {
+	keys, err := c.list(proto.Consistency_MAJORITY)
+	if err != nil {
+		return err
+	}
+	sorted := append([]string(nil), keys.GetKeys()...)
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		if job.skip(key) {
+			continue
+		}
+		resp, err := c.read(key, proto.Consistency_MAJORITY)
+		if err != nil {
+			return err
+		}
+		if resp.GetOK() {
+			wresp, err := c.writeAt(key, resp.GetValue(), resp.GetTime(), proto.Consistency_MAJORITY, goal, goalCfg)
+			if err != nil {
+				return err
+			}
+			if wresp.GetFenced() {
+				// a newer configuration was announced and stopped the goal configuration
+				return ErrConfigSuperseded
+			}
+		}
+		if err := job.transferred(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newPeerClient returns the client that a server uses to pull the state from other servers.
+// It parses the old configurations, and its manager keeps one connection to each old server,
+// which is dialed by the first stream from that server and reused by later ones, see stream.
+// Servers only pull the state of configurations without Byzantine servers, so it verifies no signatures
+// and has no keyring.
+func newPeerClient() *client {
+	return &client{mgr: newPeerManager(), known: newKnownConfigs()}
+}
+
+// newPeerManager returns the manager of a peer client.
+// It does not dial any server until a configuration is created.
+func newPeerManager() *proto.Manager {
+	return proto.NewManager(
+		gorums.WithDialTimeout(1*time.Second),
+		gorums.WithGrpcDialOptions(
+			grpc.WithBlock(), // block until connections are made
+			grpc.WithTransportCredentials(insecure.NewCredentials()), // disable TLS
+		),
+	)
+}
+
+// streamed is what a new server has received from a single old server.
+type streamed struct {
+	received uint64
+	// end is the last key received, or the request's After if none was
+	end string
+	// more is set if the server stopped at the limit, and fenced if it knows a newer started configuration
+	more   bool
+	fenced bool
+}
+
+// pulled is what a new server has pulled from an old configuration.
+type pulled struct {
+	received uint64
+	fenced   bool
+	// complete is set if all keys after the request's After were pulled;
+	// otherwise the keys up to and including cursor were
+	complete bool
+	cursor   string
+}
+
+// pull streams the values after req.After, up to req.Limit values per server, from the servers of conf to apply.
+// It returns once a read quorum of conf has sent all requested values.
+// The streams of slower servers are canceled, and pull waits for them to end,
+// so that no values are applied after it returns.
+func (c *client) pull(conf *proto.MetaConfig, req *proto.TransferRequest, apply func([]*proto.Entry)) (pulled, error) {
+	system, nodes, err := c.parseQuorumSystem(conf)
+	if err != nil {
+		return pulled{}, err
+	}
+	ids := make([]uint32, len(nodes))
+	for i, addr := range nodes {
+		server, err := newServer(addr)
+		if err != nil {
+			return pulled{}, &ConfigError{Config: conf.GetAdds(), Cause: err}
+		}
+		ids[i] = server.GetID()
+	}
+	system.bind(ids)
+
+	type result struct {
+		id  uint32
+		s   streamed
+		err error
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	results := make(chan result, len(nodes))
+	for i, addr := range nodes {
+		wg.Add(1)
+		go func(id uint32, addr string) {
+			defer wg.Done()
+			s, err := c.stream(ctx, id, addr, req, apply)
+			results <- result{id: id, s: s, err: err}
+		}(ids[i], addr)
+	}
+	var p pulled
+	ends := make(map[uint32]string)
+	more := make(map[uint32]bool)
+	var errs []NodeError
+	for range nodes {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, NodeError{NodeID: r.id, Cause: r.err})
+			continue
+		}
+		p.received += r.s.received
+		if r.s.fenced {
+			p.fenced = true
+			return p, nil
+		}
+		ends[r.id], more[r.id] = r.s.end, r.s.more
+		if system.isReadQuorum(qf.IDs(more)) {
+			p.cursor, p.complete = chunkCursor(system, ends, more)
+			return p, nil
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].NodeID < errs[j].NodeID })
+	return p, &QuorumError{Method: "TransferStateQC", Reason: "incomplete call", Replies: len(more), Nodes: errs}
+}
+
+// chunkCursor returns the largest key up to which a read quorum of system has sent all its values,
+// given the last key sent by each server, and whether a read quorum has sent all values.
+// more is set for the servers that stopped at the limit.
+func chunkCursor(system quorumSystem, ends map[uint32]string, more map[uint32]bool) (cursor string, complete bool) {
+	ids := make([]uint32, 0, len(more))
+	for id := range more {
+		ids = append(ids, id)
+	}
+	// the servers that sent all their values first, then the others by their last key, the largest first
+	sort.Slice(ids, func(i, j int) bool {
+		if more[ids[i]] != more[ids[j]] {
+			return !more[ids[i]]
+		}
+		return ends[ids[i]] > ends[ids[j]]
+	})
+	quorum := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		quorum[id] = true
+		if system.isReadQuorum(quorum) {
+			return ends[id], !more[id]
+		}
+	}
+	return "", false
+}
+
+// stream receives the values after req.After, up to req.Limit values, from the server with the given ID and address,
+// and applies them batch by batch. It asks for one batch after the other with TransferStateQC,
+// until the server has no more values, stops at the limit, or is fenced.
+func (c *client) stream(ctx context.Context, id uint32, addr string, req *proto.TransferRequest, apply func([]*proto.Entry)) (streamed, error) {
+	s := streamed{end: req.GetAfter()}
+	cfg, err := c.nodeConfiguration(id, addr)
+	if err != nil {
+		return s, err
+	}
+	for {
+		limit := uint64(transferBatchSize)
+		if req.GetLimit() > 0 && uint64(req.GetLimit())-s.received < limit {
+			limit = uint64(req.GetLimit()) - s.received
+		}
+		b, err := cfg.TransferStateQC(ctx, &proto.TransferRequest{After: s.end, Config: req.GetConfig(), Limit: uint32(limit)})
+		if err != nil {
+			return s, err
+		}
+		if b.GetFenced() {
+			s.fenced = true
+			return s, nil
+		}
+		entries := b.GetEntries()
+		if len(entries) == 0 && b.GetMore() {
+			return s, fmt.Errorf("transfer made no progress after '%s'", s.end)
+		}
+		apply(entries)
+		s.received += uint64(len(entries))
+		if n := len(entries); n > 0 {
+			s.end = entries[n-1].GetKey()
+		}
+		if !b.GetMore() {
+			return s, nil
+		}
+		if req.GetLimit() > 0 && s.received >= uint64(req.GetLimit()) {
+			s.more = true
+			return s, nil
+		}
+	}
+}
+
+// PullStateQC is an RPC handler for a quorum call
+func (s *storageServer) PullStateQC(ctx gorums.ServerCtx, req *proto.PullRequest) (*proto.PullResponse, error) {
+	// pulling takes long; let the server handle other requests meanwhile
+	ctx.Release()
+	return s.PullState(req)
+}
+
+// TransferStateQC is an RPC handler for a quorum call
+func (s *storageServer) TransferStateQC(ctx gorums.ServerCtx, req *proto.TransferRequest) (*proto.TransferBatch, error) {
+	ctx.Release()
+	return s.Transfer(req)
+}
+
+// PullState pulls the values of the keys after req.After from a read quorum of each old configuration in req.Sources,
+// up to req.Limit values from each server, and stores the newest value of each key. Values are stored as they arrive, since any value from an old server
+// is a value that was written; a newer value from another server replaces it.
+func (s *storageServer) PullState(req *proto.PullRequest) (*proto.PullResponse, error) {
+	s.logger.Printf("Pull state after '%s' from %d configurations\n", req.GetAfter(), len(req.GetSources()))
+	s.mut.RLock()
+	decommissioned, stopped := s.decommissioned, s.stopped(req.GetConfig())
+	s.mut.RUnlock()
+	if decommissioned {
+		return nil, errDecommissioned
+	}
+	if stopped {
+		return &proto.PullResponse{Fenced: true}, nil
+	}
+
+	resp := &proto.PullResponse{Done: true}
+	treq := &proto.TransferRequest{After: req.GetAfter(), Config: req.GetConfig(), Limit: req.GetLimit()}
+	for _, conf := range req.GetSources() {
+		p, err := s.peers.pull(conf, treq, s.restore)
+		if err != nil {
+			return nil, fmt.Errorf("transfer from '%s': %w", conf.GetAdds(), err)
+		}
+		if p.fenced {
+			return &proto.PullResponse{Fenced: true}, nil
+		}
+		resp.Received += p.received
+		// the keys up to the smallest cursor have been pulled from all old configurations
+		if !p.complete && (resp.GetDone() || p.cursor < resp.GetCursor()) {
+			resp.Done, resp.Cursor = false, p.cursor
+		}
+	}
+	if resp.GetDone() {
+		s.logger.Printf("Pulled %d values\n", resp.GetReceived())
+	} else {
+		s.logger.Printf("Pulled %d values up to '%s'\n", resp.GetReceived(), resp.GetCursor())
+	}
+	return resp, nil
+}
+
+// Transfer returns the batch of the values of the keys after req.After, ordered by key,
+// with at most req.Limit values and at most transferBatchSize.
+// The old configuration is stopped, so that its servers accept no more writes,
+// and the batches of consecutive calls are thus consistent.
+func (s *storageServer) Transfer(req *proto.TransferRequest) (*proto.TransferBatch, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	if s.decommissioned {
+		return nil, errDecommissioned
+	}
+	if s.fenced(req.GetConfig()) {
+		return &proto.TransferBatch{Fenced: true}, nil
+	}
+	keys := make([]string, 0, len(s.storage))
+	for k := range s.storage {
+		if k > req.GetAfter() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	limit := transferBatchSize
+	if l := int(req.GetLimit()); l > 0 && l < limit {
+		limit = l
+	}
+	more := false
+	if len(keys) > limit {
+		keys, more = keys[:limit], true
+	}
+	entries := make([]*proto.Entry, len(keys))
+	for i, k := range keys {
+		st := s.storage[k]
+		entries[i] = &proto.Entry{Key: k, Value: st.Value, Time: timestamppb.New(st.Time), Signature: st.Signature, Writer: st.Writer}
+	}
+	return &proto.TransferBatch{Entries: entries, More: more}, nil
+}
+
+// restore stores the transferred values that are not older than the stored values.
+func (s *storageServer) restore(entries []*proto.Entry) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for _, e := range entries {
+		if old, ok := s.storage[e.GetKey()]; ok && old.Time.After(e.GetTime().AsTime()) {
+			continue
+		}
+		s.storage[e.GetKey()] = state{Value: e.GetValue(), Time: e.GetTime().AsTime(), Signature: e.GetSignature(), Writer: e.GetWriter()}
+	}
+}
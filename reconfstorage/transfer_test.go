@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"reconfstorage/proto"
+)
+
+func TestChunkCursor(t *testing.T) {
+	majority, err := newVotingSystem(3, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := bindTest(majority, 3)
+	tests := []struct {
+		name     string
+		ends     map[uint32]string
+		more     map[uint32]bool
+		cursor   string
+		complete bool
+	}{
+		{"all sent", map[uint32]string{1: "c", 2: "c"}, map[uint32]bool{1: false, 2: false}, "c", true},
+		{"one at the limit", map[uint32]string{1: "c", 2: "f"}, map[uint32]bool{1: false, 2: true}, "f", false},
+		{"both at the limit", map[uint32]string{1: "d", 2: "f"}, map[uint32]bool{1: true, 2: true}, "d", false},
+		{"quorum without the slowest", map[uint32]string{1: "d", 2: "f", 3: "h"}, map[uint32]bool{1: true, 2: true, 3: true}, "f", false},
+	}
+	for _, test := range tests {
+		cursor, complete := chunkCursor(system, test.ends, test.more)
+		if cursor != test.cursor || complete != test.complete {
+			t.Errorf("%s: chunkCursor = %q, %v; want %q, %v", test.name, cursor, complete, test.cursor, test.complete)
+		}
+	}
+}
+
+func TestTransferLimit(t *testing.T) {
+	s := newTestServer()
+	for i := 0; i < 250; i++ {
+		s.storage[fmt.Sprintf("k%03d", i)] = state{Value: "v", Time: time.Unix(1, 0)}
+	}
+	b, err := s.Transfer(&proto.TransferRequest{After: "k009", Limit: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(b.GetEntries()); n != transferBatchSize || !b.GetMore() || b.GetEntries()[0].GetKey() != "k010" {
+		t.Errorf("sent %d values from %s, more %t; want one batch of %d values from k010 and more", n, b.GetEntries()[0].GetKey(), b.GetMore(), transferBatchSize)
+	}
+	if b, _ := s.Transfer(&proto.TransferRequest{After: "k109", Limit: 50}); len(b.GetEntries()) != 50 || !b.GetMore() {
+		t.Errorf("sent %d values, more %t; want 50 values and more", len(b.GetEntries()), b.GetMore())
+	}
+	if b, _ := s.Transfer(&proto.TransferRequest{After: "k199"}); len(b.GetEntries()) != 50 || b.GetMore() {
+		t.Errorf("sent %d values, more %t; want the last 50 values", len(b.GetEntries()), b.GetMore())
+	}
+}
+
+// TestStreamLimit pulls a chunk from a single server batch by batch, over the connection of the peer client.
+func TestStreamLimit(t *testing.T) {
+	c, addrs := newTestCluster(t, 1)
+	for i := 0; i < 250; i++ {
+		if _, err := c.write(fmt.Sprintf("k%03d", i), "v", proto.Consistency_MAJORITY); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server, err := newServer(addrs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	peers := newPeerClient()
+	defer peers.mgr.Close()
+	var applied []string
+	apply := func(entries []*proto.Entry) {
+		for _, e := range entries {
+			applied = append(applied, e.GetKey())
+		}
+	}
+	req := &proto.TransferRequest{After: "k009", Config: c.current().GetTime(), Limit: 150}
+	got, err := peers.stream(context.Background(), server.GetID(), server.GetAddress(), req, apply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.received != 150 || got.end != "k159" || !got.more || len(applied) != 150 || applied[0] != "k010" {
+		t.Errorf("stream = %+v, applied %d values; want 150 values from k010 up to k159 and more", got, len(applied))
+	}
+	req.After = got.end
+	if got, err = peers.stream(context.Background(), server.GetID(), server.GetAddress(), req, apply); err != nil || got.end != "k249" || got.more {
+		t.Errorf("stream = %+v, %v; want the rest up to k249", got, err)
+	}
+	if len(peers.nodeConfigs) != 1 {
+		t.Errorf("the peer client has %d node configurations, want one reused connection", len(peers.nodeConfigs))
+	}
+}
+
+// TestPullCancels checks that pull applies no values after it returns, although it returns after a read quorum.
+func TestPullCancels(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	for i := 0; i < 500; i++ {
+		if _, err := c.write(fmt.Sprintf("k%03d", i), "v", proto.Consistency_MAJORITY); err != nil {
+			t.Fatal(err)
+		}
+	}
+	peers := newPeerClient()
+	defer peers.mgr.Close()
+	var (
+		mu       sync.Mutex
+		returned bool
+		late     int
+	)
+	apply := func(entries []*proto.Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		if returned {
+			late += len(entries)
+		}
+	}
+	p, err := peers.pull(c.current(), &proto.TransferRequest{Config: c.current().GetTime()}, apply)
+	mu.Lock()
+	returned = true
+	mu.Unlock()
+	if err != nil || !p.complete || p.received < 2*500 {
+		t.Fatalf("pull = %+v, %v; want all values from a read quorum", p, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if late > 0 {
+		t.Errorf("%d values were applied after pull returned", late)
+	}
+}
+
+func TestPullStateQCQFCursor(t *testing.T) {
+	q := newTestQSpec(t, 3)
+	in := &proto.PullRequest{}
+	if _, done := q.PullStateQCQF(in, map[uint32]*proto.PullResponse{1: {Cursor: "f"}}); done {
+		t.Error("done with one reply of three")
+	}
+	resp, done := q.PullStateQCQF(in, map[uint32]*proto.PullResponse{1: {Cursor: "f", Received: 2}, 2: {Cursor: "d", Received: 3}, 3: {Done: true}})
+	if !done || resp.GetDone() || resp.GetCursor() != "d" || resp.GetReceived() != 5 {
+		t.Errorf("PullStateQCQF = %v, want cursor d and 5 values", resp)
+	}
+	resp, _ = q.PullStateQCQF(in, map[uint32]*proto.PullResponse{1: {Done: true}, 2: {Done: true}})
+	if !resp.GetDone() {
+		t.Errorf("PullStateQCQF = %v, want done", resp)
+	}
+	resp, _ = q.PullStateQCQF(in, map[uint32]*proto.PullResponse{1: {Fenced: true}})
+	if !resp.GetFenced() {
+		t.Errorf("PullStateQCQF = %v, want fenced", resp)
+	}
+}
+
+// TestWriteRacingAnnouncement writes while the storage is reconfigured. Every write that completes
+// must be visible in the new configuration, whether it reached the old servers before or after the stop sign.
+func TestWriteRacingAnnouncement(t *testing.T) {
+	c, addrs := newTestCluster(t, 3)
+	srv, extra := startServer("127.0.0.1:0")
+	t.Cleanup(srv.Stop)
+	w := newClient(addrs, newKeyring())
+
+	done := make(chan struct{})
+	written := make(chan []string)
+	go func() {
+		var keys []string
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				written <- keys
+				return
+			default:
+			}
+			key := fmt.Sprintf("k%03d", i)
+			if _, err := w.write(key, key, proto.Consistency_MAJORITY); err == nil {
+				keys = append(keys, key)
+			}
+		}
+	}()
+	// let some writes complete before the announcement
+	time.Sleep(20 * time.Millisecond)
+	err := c.reconf(&proto.MetaConfig{Adds: configString([]string{addrs[1], addrs[2], extra}, "")})
+	// and some after the new configuration is started
+	time.Sleep(20 * time.Millisecond)
+	close(done)
+	keys := <-written
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) == 0 {
+		t.Fatal("no write completed")
+	}
+	for _, key := range keys {
+		if r, err := c.read(key, proto.Consistency_MAJORITY); err != nil || r.GetValue() != key {
+			t.Errorf("read %s from the new configuration = %q, %v; want the completed write", key, r.GetValue(), err)
+		}
+	}
+}